@@ -0,0 +1,196 @@
+package confstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Redacted is the placeholder written in place of hidden values: by View with
+// WithRedaction, by MarshalRedacted and by the manifest package.
+const Redacted = "REDACTED"
+
+// Principal describes an in-process consumer of configuration, such as a
+// plugin, and the roles or scopes it has been granted.
+type Principal struct {
+	Name  string
+	Roles []string
+}
+
+// AccessPolicy decides whether p may see the field at path (dotted json
+// names, e.g. "db.password"), given the roles listed in the field's access tag.
+type AccessPolicy func(p Principal, path string, roles []string) bool
+
+// AnyRole is the default AccessPolicy: a field is visible if the principal
+// holds at least one of the roles it requires.
+func AnyRole(p Principal, _ string, roles []string) bool {
+	for _, r := range roles {
+		if slices.Contains(p.Roles, r) {
+			return true
+		}
+	}
+	return false
+}
+
+type viewOptions struct {
+	policy AccessPolicy
+	redact bool
+}
+
+// ViewOption configures View.
+type ViewOption func(*viewOptions)
+
+// WithPolicy replaces the default AnyRole policy.
+func WithPolicy(policy AccessPolicy) ViewOption { return func(o *viewOptions) { o.policy = policy } }
+
+// WithRedaction replaces denied string values with Redacted instead of
+// clearing them, so consumers can tell a hidden value from an unset one.
+// Denied values of other types are still cleared.
+func WithRedaction() ViewOption { return func(o *viewOptions) { o.redact = true } }
+
+// View returns a copy of config containing only what p is allowed to see.
+// Fields are restricted with an access tag listing the roles that may read
+// them:
+//
+//	type AppConf struct {
+//		Addr string `json:"addr"`
+//		DB   struct {
+//			DSN string `json:"dsn" access:"db-admin"`
+//		} `json:"db"`
+//		Billing BillingConf `json:"billing" access:"billing,admin"`
+//	}
+//
+// Denied fields, including everything below a denied struct, are left at
+// their zero value. Fields without an access tag are visible to everyone,
+// except fields tagged secret:"true", which are denied unless an access tag
+// grants them to p.
+// Slice elements are checked with the path of the slice itself, map values
+// with the map path followed by their key. The copy is made through the JSON
+// representation of T, so config is never modified and the view shares no
+// memory with it.
+func View[T any](config *T, p Principal, opts ...ViewOption) (*T, error) {
+	o := &viewOptions{policy: AnyRole}
+	for _, opt := range opts {
+		opt(o)
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("confstore: view encode config: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err = dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("confstore: view decode config: %w", err)
+	}
+	tree = filterTree(reflect.TypeOf(config).Elem(), tree, "", func(field reflect.StructField, path string, v any) (any, bool) {
+		roles, restricted := field.Tag.Lookup("access")
+		if restricted && o.policy(p, path, splitRoles(roles)) || !restricted && !isSecret(field) {
+			return v, true
+		}
+		if _, isString := v.(string); isString && o.redact {
+			return Redacted, true
+		}
+		return nil, false
+	})
+	if data, err = json.Marshal(tree); err != nil {
+		return nil, fmt.Errorf("confstore: view encode filtered config: %w", err)
+	}
+	view := new(T)
+	if err = json.Unmarshal(data, view); err != nil {
+		return nil, fmt.Errorf("confstore: view decode filtered config: %w", err)
+	}
+	return view, nil
+}
+
+// fieldFilter decides what happens to the JSON value v of a struct field
+// at path: it returns the value to keep, or false to drop the field.
+// Returning v unchanged keeps filtering below it.
+type fieldFilter func(field reflect.StructField, path string, v any) (any, bool)
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// filterTree applies keep to every struct field of the decoded JSON tree v,
+// walking it alongside its Go type t.
+func filterTree(t reflect.Type, v any, path string, keep fieldFilter) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		// Custom encoding: the JSON shape does not follow the Go type.
+		return v
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if obj, ok := v.(map[string]any); ok {
+			filterFields(t, obj, path, keep)
+		}
+	case reflect.Slice, reflect.Array:
+		if arr, ok := v.([]any); ok {
+			for i := range arr {
+				arr[i] = filterTree(t.Elem(), arr[i], path, keep)
+			}
+		}
+	case reflect.Map:
+		if obj, ok := v.(map[string]any); ok {
+			for k, child := range obj {
+				obj[k] = filterTree(t.Elem(), child, joinPath(path, k), keep)
+			}
+		}
+	}
+	return v
+}
+
+func filterFields(t reflect.Type, obj map[string]any, path string, keep fieldFilter) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			// Embedded struct without a json name: its fields are inlined.
+			filterFields(ft, obj, path, keep)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		child, ok := obj[name]
+		if !ok {
+			continue
+		}
+		fieldPath := joinPath(path, name)
+		kept, ok := keep(field, fieldPath, child)
+		if !ok {
+			delete(obj, name)
+			continue
+		}
+		obj[name] = filterTree(field.Type, kept, fieldPath, keep)
+	}
+}
+
+func splitRoles(tag string) []string {
+	var roles []string
+	for _, r := range strings.Split(tag, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			roles = append(roles, r)
+		}
+	}
+	return roles
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
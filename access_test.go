@@ -0,0 +1,99 @@
+package confstore
+
+import (
+	"strings"
+	"testing"
+)
+
+type accessDB struct {
+	Host     string `json:"host"`
+	Password string `json:"password" access:"db-admin"`
+	Port     int    `json:"port" access:"db-admin"`
+}
+
+type accessConf struct {
+	Addr    string              `json:"addr"`
+	DB      accessDB            `json:"db"`
+	Billing *accessDB           `json:"billing" access:"billing, admin"`
+	Tenants map[string]accessDB `json:"tenants"`
+}
+
+func newAccessConf() *accessConf {
+	return &accessConf{
+		Addr:    ":8080",
+		DB:      accessDB{Host: "db", Password: "secret", Port: 5432},
+		Billing: &accessDB{Host: "billing"},
+		Tenants: map[string]accessDB{"acme": {Host: "acme-db", Password: "acme-secret"}},
+	}
+}
+
+func TestView_Filters(t *testing.T) {
+	conf := newAccessConf()
+	view, err := View(conf, Principal{Name: "plugin", Roles: []string{"admin"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if view.Addr != ":8080" || view.DB.Host != "db" || view.Tenants["acme"].Host != "acme-db" {
+		t.Fatalf("unrestricted fields missing: %+v", view)
+	}
+	if view.DB.Password != "" || view.DB.Port != 0 || view.Tenants["acme"].Password != "" {
+		t.Fatalf("restricted fields leaked: %+v", view)
+	}
+	if view.Billing == nil || view.Billing.Host != "billing" {
+		t.Fatalf("admin should see billing: %+v", view.Billing)
+	}
+	if conf.DB.Password != "secret" || conf.Tenants["acme"].Password != "acme-secret" {
+		t.Fatalf("original config modified: %+v", conf)
+	}
+
+	view, err = View(conf, Principal{Roles: []string{"db-admin"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if view.Billing != nil || view.DB.Password != "secret" || view.DB.Port != 5432 {
+		t.Fatalf("unexpected db-admin view: %+v", view)
+	}
+}
+
+func TestView_RedactionAndPolicy(t *testing.T) {
+	var paths []string
+	policy := func(p Principal, path string, roles []string) bool {
+		paths = append(paths, path)
+		return strings.HasPrefix(path, "tenants.")
+	}
+	view, err := View(newAccessConf(), Principal{}, WithPolicy(policy), WithRedaction())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if view.DB.Password != Redacted || view.DB.Port != 0 {
+		t.Fatalf("expected redacted password and cleared port: %+v", view.DB)
+	}
+	if view.Tenants["acme"].Password != "acme-secret" {
+		t.Fatalf("policy should allow tenant fields: %+v", view.Tenants)
+	}
+	for _, want := range []string{"db.password", "billing", "tenants.acme.password"} {
+		found := false
+		for _, p := range paths {
+			found = found || p == want
+		}
+		if !found {
+			t.Fatalf("policy not consulted for %s (got %v)", want, paths)
+		}
+	}
+}
+
+func TestView_SecretFields(t *testing.T) {
+	type conf struct {
+		Addr     string `json:"addr"`
+		Token    string `json:"token" secret:"true"`
+		AdminKey string `json:"adminKey" secret:"true" access:"admin"`
+	}
+	c := &conf{Addr: ":8080", Token: "t0k3n", AdminKey: "k3y"}
+	view, err := View(c, Principal{Roles: []string{"admin"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if view.Addr != ":8080" || view.Token != "" || view.AdminKey != "k3y" {
+		t.Fatalf("secret fields need an access tag granting them: %+v", view)
+	}
+}
@@ -0,0 +1,139 @@
+// Package bundle produces and verifies signed configuration bundles.
+//
+// A bundle carries the configuration payload, a manifest with the payload's
+// SHA-256 digest and metadata, and an Ed25519 signature over the exact
+// manifest bytes. Publishers create bundles with Sign; consumers wrap the
+// provider that fetches them with NewVerifier so that only payloads signed by
+// a trusted key ever reach the codec.
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sphere/confstore/provider"
+)
+
+var (
+	// ErrMalformed indicates that a bundle could not be parsed.
+	ErrMalformed = errors.New("bundle: malformed bundle")
+	// ErrUntrustedKey indicates that a bundle was signed by a key that is not trusted.
+	ErrUntrustedKey = errors.New("bundle: untrusted signing key")
+	// ErrBadSignature indicates that the manifest signature is invalid.
+	ErrBadSignature = errors.New("bundle: invalid signature")
+	// ErrDigestMismatch indicates that the payload does not match the signed manifest.
+	ErrDigestMismatch = errors.New("bundle: payload digest mismatch")
+)
+
+// Manifest is the signed description of a bundle payload.
+type Manifest struct {
+	// KeyID identifies the signing key; see KeyID.
+	KeyID string `json:"key_id"`
+	// SHA256 is the hex-encoded digest of the payload.
+	SHA256 string `json:"sha256"`
+	// Size is the payload size in bytes.
+	Size int64 `json:"size"`
+	// Version is an optional caller-defined config version.
+	Version string `json:"version,omitempty"`
+	// CreatedAt is the signing time.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type envelope struct {
+	Manifest  []byte `json:"manifest"`
+	Signature []byte `json:"signature"`
+	Payload   []byte `json:"payload"`
+}
+
+// KeyID returns a short, stable identifier for a public key, used to select
+// the verification key when several are trusted during key rotation.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Sign creates a bundle for payload signed with key. version is recorded in the manifest and may be empty.
+func Sign(payload []byte, key ed25519.PrivateKey, version string) ([]byte, error) {
+	pub, ok := key.Public().(ed25519.PublicKey)
+	if !ok || len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("bundle: invalid private key")
+	}
+	sum := sha256.Sum256(payload)
+	manifest, err := json.Marshal(Manifest{
+		KeyID:     KeyID(pub),
+		SHA256:    hex.EncodeToString(sum[:]),
+		Size:      int64(len(payload)),
+		Version:   version,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope{
+		Manifest:  manifest,
+		Signature: ed25519.Sign(key, manifest),
+		Payload:   payload,
+	})
+}
+
+// Verify checks a bundle against the trusted public keys and returns its payload and manifest.
+func Verify(data []byte, trusted ...ed25519.PublicKey) ([]byte, *Manifest, error) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(env.Manifest, &m); err != nil {
+		return nil, nil, fmt.Errorf("%w: manifest: %w", ErrMalformed, err)
+	}
+	var key ed25519.PublicKey
+	for _, k := range trusted {
+		if KeyID(k) == m.KeyID {
+			key = k
+			break
+		}
+	}
+	if key == nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUntrustedKey, m.KeyID)
+	}
+	if !ed25519.Verify(key, env.Manifest, env.Signature) {
+		return nil, nil, ErrBadSignature
+	}
+	sum := sha256.Sum256(env.Payload)
+	if hex.EncodeToString(sum[:]) != m.SHA256 || int64(len(env.Payload)) != m.Size {
+		return nil, nil, ErrDigestMismatch
+	}
+	return env.Payload, &m, nil
+}
+
+// Verifier is a Provider adapter that reads a bundle from the wrapped provider,
+// verifies it, and returns only the payload.
+type Verifier struct {
+	provider provider.Provider
+	trusted  []ed25519.PublicKey
+}
+
+// NewVerifier wraps p so that Read returns the verified payload of the bundle p returns.
+func NewVerifier(p provider.Provider, trusted ...ed25519.PublicKey) *Verifier {
+	return &Verifier{provider: p, trusted: trusted}
+}
+
+// Read implements provider.Provider.
+func (v *Verifier) Read(ctx context.Context) ([]byte, error) {
+	data, err := v.provider.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	payload, _, err := Verify(bytes.TrimSpace(data), v.trusted...)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
@@ -0,0 +1,84 @@
+package bundle
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-sphere/confstore/provider"
+)
+
+func newKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return pub, priv
+}
+
+func TestSignAndVerify(t *testing.T) {
+	pub, priv := newKey(t)
+	oldPub, _ := newKey(t)
+	payload := []byte(`{"addr":":8080"}`)
+	b, err := Sign(payload, priv, "v3")
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+	got, m, err := Verify(b, oldPub, pub)
+	if err != nil {
+		t.Fatalf("Verify error: %v", err)
+	}
+	if string(got) != string(payload) || m.Version != "v3" || m.KeyID != KeyID(pub) {
+		t.Fatalf("unexpected result: %q %+v", got, m)
+	}
+}
+
+func TestVerifyFailures(t *testing.T) {
+	pub, priv := newKey(t)
+	otherPub, _ := newKey(t)
+	b, err := Sign([]byte("payload"), priv, "")
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+	if _, _, err = Verify(b, otherPub); !errors.Is(err, ErrUntrustedKey) {
+		t.Fatalf("expected ErrUntrustedKey, got %v", err)
+	}
+
+	var env envelope
+	_ = json.Unmarshal(b, &env)
+	env.Payload = []byte("tampered")
+	tampered, _ := json.Marshal(env)
+	if _, _, err = Verify(tampered, pub); !errors.Is(err, ErrDigestMismatch) {
+		t.Fatalf("expected ErrDigestMismatch, got %v", err)
+	}
+
+	_ = json.Unmarshal(b, &env)
+	env.Manifest = []byte(string(env.Manifest[:len(env.Manifest)-1]) + `,"version":"x"}`)
+	forged, _ := json.Marshal(env)
+	if _, _, err = Verify(forged, pub); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("expected ErrBadSignature, got %v", err)
+	}
+
+	if _, _, err = Verify([]byte("not a bundle"), pub); !errors.Is(err, ErrMalformed) {
+		t.Fatalf("expected ErrMalformed, got %v", err)
+	}
+}
+
+func TestVerifierProvider(t *testing.T) {
+	pub, priv := newKey(t)
+	b, err := Sign([]byte(`{"mode":"prod"}`), priv, "")
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+	src := provider.ReaderFunc(func(ctx context.Context) ([]byte, error) { return b, nil })
+	got, err := NewVerifier(src, pub).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if string(got) != `{"mode":"prod"}` {
+		t.Fatalf("got %q", got)
+	}
+}
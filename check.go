@@ -0,0 +1,205 @@
+package confstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-sphere/confstore/codec"
+	"github.com/go-sphere/confstore/provider"
+	"github.com/go-sphere/confstore/schema"
+)
+
+// IssueKind classifies a problem found by Check.
+type IssueKind string
+
+const (
+	// IssueSyntax reports a document the codec could not parse.
+	IssueSyntax IssueKind = "syntax"
+	// IssueUnknownKey reports a key that does not correspond to any field of the target.
+	IssueUnknownKey IssueKind = "unknown_key"
+	// IssueTypeMismatch reports a value whose type does not match the target field.
+	IssueTypeMismatch IssueKind = "type_mismatch"
+	// IssueMissingRequired reports a missing field tagged `required:"true"`.
+	IssueMissingRequired IssueKind = "missing_required"
+	// IssueInvalidValue reports a value rejected for another reason, e.g. outside an enum.
+	IssueInvalidValue IssueKind = "invalid_value"
+)
+
+// Issue is a single problem found in a configuration document.
+// Line and Column are 1-based and zero when the position is unknown.
+type Issue struct {
+	Kind    IssueKind
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// String formats the issue as "path (line:column): message".
+func (i Issue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s (%d:%d): %s", i.Path, i.Line, i.Column, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.Path, i.Message)
+}
+
+// Report lists the issues found by Check.
+type Report struct {
+	Issues []Issue
+}
+
+// OK reports whether no issues were found.
+func (r *Report) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// Check reads a configuration document and checks it against the type of
+// target without populating target. It reports unknown keys, type mismatches
+// and missing required fields, following the same tag rules as the schema
+// package. Positions are reported when the document is JSON.
+//
+// The returned error covers failures to perform the check, such as a provider
+// read error or an unsupported target type; problems in the document itself
+// are reported as issues.
+func Check(ctx context.Context, provider provider.Provider, codec codec.Codec, target any) (*Report, error) {
+	s, err := schema.Generate(target)
+	if err != nil {
+		return nil, err
+	}
+	data, err := provider.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	var doc any
+	if err = codec.Unmarshal(data, &doc); err != nil {
+		report.Issues = append(report.Issues, decodeIssue(IssueSyntax, err))
+		return report, nil
+	}
+	positions := jsonPositions(data)
+	for _, v := range s.Violations(doc) {
+		report.Issues = append(report.Issues, violationIssue(v, data, positions))
+	}
+	if report.OK() {
+		// The schema cannot express everything (custom unmarshalers, numeric
+		// ranges), so confirm with a real decode into a throwaway value.
+		tmp := reflect.New(indirectType(reflect.TypeOf(target)))
+		if err = codec.Unmarshal(data, tmp.Interface()); err != nil {
+			kind := IssueInvalidValue
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				kind = IssueTypeMismatch
+			}
+			report.Issues = append(report.Issues, decodeIssue(kind, err))
+		}
+	}
+	return report, nil
+}
+
+// violationIssue converts a schema violation into an Issue, locating it in
+// data through the precomputed JSON positions when available.
+func violationIssue(v schema.Violation, data []byte, positions map[string]int64) Issue {
+	issue := Issue{Path: v.Path, Message: v.Message}
+	switch v.Kind {
+	case schema.KindUnknown:
+		issue.Kind = IssueUnknownKey
+	case schema.KindType:
+		issue.Kind = IssueTypeMismatch
+	case schema.KindRequired:
+		issue.Kind = IssueMissingRequired
+	default:
+		issue.Kind = IssueInvalidValue
+	}
+	if off, ok := positions[v.Path]; ok {
+		issue.Line, issue.Column = codec.LineColumn(data, off)
+	}
+	return issue
+}
+
+// decodeIssue converts a codec error into an Issue, using the path and
+// position of a codec.DecodeError when available.
+func decodeIssue(kind IssueKind, err error) Issue {
+	issue := Issue{Kind: kind, Path: "$", Message: err.Error()}
+	var decodeErr *codec.DecodeError
+	if errors.As(err, &decodeErr) {
+		if decodeErr.Path != "" {
+			issue.Path = "$." + decodeErr.Path
+		}
+		issue.Line, issue.Column = decodeErr.Line, decodeErr.Column
+	}
+	return issue
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonPositions maps schema-style paths ("$", "$.a.b", "$.list[0]") to the byte
+// offset where the key (for object members) or value (for the root and array
+// items) starts. It returns nil if data is not valid JSON.
+func jsonPositions(data []byte) map[string]int64 {
+	if !json.Valid(data) {
+		return nil
+	}
+	positions := make(map[string]int64)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	positions["$"] = nextTokenOffset(data, 0)
+	_ = indexJSONValue(dec, data, "$", positions)
+	return positions
+}
+
+func indexJSONValue(dec *json.Decoder, data []byte, path string, positions map[string]int64) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+	switch delim {
+	case '{':
+		for dec.More() {
+			off := nextTokenOffset(data, dec.InputOffset())
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			child := path + "." + fmt.Sprint(key)
+			positions[child] = off
+			if err = indexJSONValue(dec, data, child, positions); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for i := 0; dec.More(); i++ {
+			child := fmt.Sprintf("%s[%d]", path, i)
+			positions[child] = nextTokenOffset(data, dec.InputOffset())
+			if err = indexJSONValue(dec, data, child, positions); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = dec.Token() // closing delimiter
+	return err
+}
+
+// nextTokenOffset skips whitespace and separators starting at off.
+func nextTokenOffset(data []byte, off int64) int64 {
+	for off < int64(len(data)) {
+		switch data[off] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			off++
+		default:
+			return off
+		}
+	}
+	return off
+}
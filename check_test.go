@@ -0,0 +1,85 @@
+package confstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-sphere/confstore/codec"
+)
+
+type checkConf struct {
+	Addr string `json:"addr" required:"true"`
+	Port int    `json:"port"`
+	DB   struct {
+		DSN string `json:"dsn" required:"true"`
+	} `json:"db"`
+}
+
+func TestCheck_Valid(t *testing.T) {
+	report, err := Check(context.Background(), staticProvider(`{"addr":":80","port":80,"db":{"dsn":"x"}}`), codec.JsonCodec(), &checkConf{})
+	if err != nil {
+		t.Fatalf("Check error: %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("unexpected issues: %v", report.Issues)
+	}
+}
+
+func TestCheck_AllowsNullReferences(t *testing.T) {
+	type conf struct {
+		DB   *checkConf        `json:"db"`
+		Tags []string          `json:"tags"`
+		Env  map[string]string `json:"env"`
+	}
+	report, err := Check(context.Background(), staticProvider(`{"db":null,"tags":null,"env":null}`), codec.JsonCodec(), &conf{})
+	if err != nil {
+		t.Fatalf("Check error: %v", err)
+	}
+	if !report.OK() {
+		t.Fatalf("unexpected issues: %v", report.Issues)
+	}
+}
+
+func TestCheck_ReportsIssuesWithPositions(t *testing.T) {
+	doc := `{
+  "port": "80",
+  "db": {},
+  "debug": true
+}`
+	var target checkConf
+	report, err := Check(context.Background(), staticProvider(doc), codec.JsonCodec(), &target)
+	if err != nil {
+		t.Fatalf("Check error: %v", err)
+	}
+	want := map[string]Issue{
+		"$":       {Kind: IssueMissingRequired, Line: 1, Column: 1},
+		"$.db":    {Kind: IssueMissingRequired, Line: 3, Column: 3},
+		"$.debug": {Kind: IssueUnknownKey, Line: 4, Column: 3},
+		"$.port":  {Kind: IssueTypeMismatch, Line: 2, Column: 3},
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("got %d issues, want %d: %v", len(report.Issues), len(want), report.Issues)
+	}
+	for _, got := range report.Issues {
+		w, ok := want[got.Path]
+		if !ok {
+			t.Fatalf("unexpected issue %v", got)
+		}
+		if got.Kind != w.Kind || got.Line != w.Line || got.Column != w.Column {
+			t.Errorf("issue %s: got %s %d:%d, want %s %d:%d", got.Path, got.Kind, got.Line, got.Column, w.Kind, w.Line, w.Column)
+		}
+	}
+	if target.Addr != "" || target.Port != 0 {
+		t.Fatalf("target was populated: %+v", target)
+	}
+}
+
+func TestCheck_SyntaxError(t *testing.T) {
+	report, err := Check(context.Background(), staticProvider("{\n  \"addr\": ,\n}"), codec.JsonCodec(), &checkConf{})
+	if err != nil {
+		t.Fatalf("Check error: %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Kind != IssueSyntax || report.Issues[0].Line != 2 {
+		t.Fatalf("unexpected issues: %v", report.Issues)
+	}
+}
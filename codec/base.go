@@ -35,10 +35,11 @@ var (
 // JsonCodec creates a codec for handling JSON serialization and deserialization.
 // It uses the standard library's json.Marshal and json.Unmarshal functions.
 // This codec can handle any type supported by the JSON package.
+// Syntax and type errors are returned as *DecodeError with the offending key path and position.
 func JsonCodec() Codec {
 	return &codec{
 		encoder: json.Marshal,
-		decoder: json.Unmarshal,
+		decoder: jsonUnmarshal,
 	}
 }
 
@@ -1,6 +1,7 @@
 package codec
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 )
@@ -14,3 +15,39 @@ func TestStringCodec_UnmarshalNilPointer(t *testing.T) {
 		t.Fatalf("expected ErrNilPointer, got %v", err)
 	}
 }
+
+type decodeTarget struct {
+	DB struct {
+		Port int `json:"port"`
+	} `json:"db"`
+}
+
+func TestJsonCodec_DecodeErrorTypeMismatch(t *testing.T) {
+	data := []byte("{\n  \"db\": {\n    \"port\": \"80\"\n  }\n}")
+	var out decodeTarget
+	err := JsonCodec().Unmarshal(data, &out)
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("expected *DecodeError, got %T: %v", err, err)
+	}
+	if de.Path != "db.port" || de.Line != 3 {
+		t.Fatalf("unexpected location: path=%q line=%d column=%d", de.Path, de.Line, de.Column)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("expected wrapped *json.UnmarshalTypeError, got %v", err)
+	}
+}
+
+func TestJsonCodec_DecodeErrorSyntax(t *testing.T) {
+	data := []byte("{\n  \"a\": 1,\n  \"b\": }")
+	var out map[string]any
+	err := JsonCodec().Unmarshal(data, &out)
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("expected *DecodeError, got %T: %v", err, err)
+	}
+	if de.Line != 3 || de.Column != 8 {
+		t.Fatalf("got line %d column %d, want 3:8", de.Line, de.Column)
+	}
+}
@@ -0,0 +1,90 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DecodeError describes an unmarshal failure together with where in the input
+// it happened. Codecs wrap format-specific errors in it so callers get a key
+// path and line/column regardless of the format; errors.As still reaches the
+// underlying error (e.g. *json.SyntaxError) through Unwrap.
+type DecodeError struct {
+	// Path is the dotted key path of the offending value, or "" if unknown.
+	Path string
+	// Offset is the byte offset in the input, or -1 if unknown.
+	Offset int64
+	// Line and Column are 1-based positions derived from Offset; zero if unknown.
+	Line   int
+	Column int
+	// Err is the underlying codec error.
+	Err error
+}
+
+// NewDecodeError builds a DecodeError, computing line and column from offset
+// within data. It is exported for third-party codecs; pass a negative offset
+// when the position is unknown.
+func NewDecodeError(data []byte, path string, offset int64, err error) *DecodeError {
+	line, column := LineColumn(data, offset)
+	if line == 0 {
+		offset = -1
+	}
+	return &DecodeError{Path: path, Offset: offset, Line: line, Column: column, Err: err}
+}
+
+func (e *DecodeError) Error() string {
+	switch {
+	case e.Path != "" && e.Line > 0:
+		return fmt.Sprintf("decode %s (line %d, column %d): %v", e.Path, e.Line, e.Column, e.Err)
+	case e.Path != "":
+		return fmt.Sprintf("decode %s: %v", e.Path, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("decode (line %d, column %d): %v", e.Line, e.Column, e.Err)
+	default:
+		return fmt.Sprintf("decode: %v", e.Err)
+	}
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// LineColumn converts a byte offset within data into 1-based line and column
+// numbers. It returns zeros for an offset outside data.
+func LineColumn(data []byte, offset int64) (line, column int) {
+	if offset < 0 || offset > int64(len(data)) {
+		return 0, 0
+	}
+	prefix := data[:offset]
+	line = bytes.Count(prefix, []byte{'\n'}) + 1
+	column = len(prefix) - bytes.LastIndexByte(prefix, '\n')
+	return line, column
+}
+
+// jsonUnmarshal is json.Unmarshal with syntax and type errors wrapped in DecodeError.
+// encoding/json reports offsets just past the offending byte or value, so the
+// position is moved back by one to point at it.
+func jsonUnmarshal(data []byte, val any) error {
+	err := json.Unmarshal(data, val)
+	if err == nil {
+		return nil
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return NewDecodeError(data, "", lastByte(syntaxErr.Offset), err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return NewDecodeError(data, typeErr.Field, lastByte(typeErr.Offset), err)
+	}
+	return err
+}
+
+func lastByte(offset int64) int64 {
+	if offset > 0 {
+		return offset - 1
+	}
+	return offset
+}
@@ -4,18 +4,74 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 )
 
 // FallbackCodecGroup implements a fallback mechanism for multiple codecs.
 // It tries each codec in order until one succeeds for both marshal and unmarshal operations.
+// After a successful Unmarshal it remembers the winning codec and tries it first
+// on subsequent calls, avoiding repeated failed parses when the same source is reloaded.
 type FallbackCodecGroup struct {
 	codecs []Codec
+	sticky bool
+
+	mu       sync.RWMutex
+	selected int // index into codecs; -1 until an Unmarshal succeeds
 }
 
 // NewCodecGroup creates a new FallbackCodecGroup with the provided codecs.
 // The codecs will be tried in the order they are provided.
 func NewCodecGroup(codecs ...Codec) *FallbackCodecGroup {
-	return &FallbackCodecGroup{codecs: codecs}
+	return &FallbackCodecGroup{codecs: codecs, selected: -1}
+}
+
+// WithSticky makes the group use the selected codec exclusively once an Unmarshal
+// has succeeded: later Unmarshal calls no longer fall back to the other codecs.
+// It returns the group for chaining and should be called before the group is in use.
+func (m *FallbackCodecGroup) WithSticky() *FallbackCodecGroup {
+	m.sticky = true
+	return m
+}
+
+// Selected returns the codec that last unmarshaled successfully, or nil if none has yet.
+func (m *FallbackCodecGroup) Selected() Codec {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.selected < 0 {
+		return nil
+	}
+	return m.codecs[m.selected]
+}
+
+// unmarshalOrder returns the codec indexes to try, selected codec first.
+func (m *FallbackCodecGroup) unmarshalOrder() []int {
+	m.mu.RLock()
+	selected := m.selected
+	m.mu.RUnlock()
+	if selected < 0 {
+		order := make([]int, len(m.codecs))
+		for i := range order {
+			order[i] = i
+		}
+		return order
+	}
+	if m.sticky {
+		return []int{selected}
+	}
+	order := make([]int, 0, len(m.codecs))
+	order = append(order, selected)
+	for i := range m.codecs {
+		if i != selected {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+func (m *FallbackCodecGroup) setSelected(i int) {
+	m.mu.Lock()
+	m.selected = i
+	m.mu.Unlock()
 }
 
 // Marshal attempts to marshal the value using each codec in order until one succeeds.
@@ -36,6 +92,7 @@ func (m *FallbackCodecGroup) Marshal(value any) ([]byte, error) {
 }
 
 // Unmarshal attempts to unmarshal the data using each codec in order until one succeeds.
+// The previously selected codec, if any, is tried first (or exclusively when sticky).
 // Returns nil on the first successful unmarshal, or an error if all codecs fail.
 func (m *FallbackCodecGroup) Unmarshal(data []byte, value any) error {
 	if len(m.codecs) == 0 {
@@ -43,12 +100,14 @@ func (m *FallbackCodecGroup) Unmarshal(data []byte, value any) error {
 	}
 	var joined error
 	rv := reflect.ValueOf(value)
-	for i, c := range m.codecs {
+	for _, i := range m.unmarshalOrder() {
+		c := m.codecs[i]
 		if rv.Kind() == reflect.Pointer && !rv.IsNil() {
 			// Decode into a temporary value to avoid partial writes.
 			tmp := reflect.New(rv.Elem().Type())
 			if err := c.Unmarshal(data, tmp.Interface()); err == nil {
 				rv.Elem().Set(tmp.Elem())
+				m.setSelected(i)
 				return nil
 			} else {
 				joined = errors.Join(joined, fmt.Errorf("codec[%d]: %w", i, err))
@@ -57,6 +116,7 @@ func (m *FallbackCodecGroup) Unmarshal(data []byte, value any) error {
 		}
 		// Fall back to decoding into the provided value (may fail for a non-pointer or nil pointer).
 		if err := c.Unmarshal(data, value); err == nil {
+			m.setSelected(i)
 			return nil
 		} else {
 			joined = errors.Join(joined, fmt.Errorf("codec[%d]: %w", i, err))
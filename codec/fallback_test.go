@@ -42,3 +42,64 @@ func TestFallbackUnmarshalFailure(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestFallbackSelectedTriedFirst(t *testing.T) {
+	var calls []string
+	c1 := testCodec{
+		unmarshal: func(data []byte, v any) error {
+			calls = append(calls, "c1")
+			return errors.New("nope1")
+		},
+	}
+	c2 := testCodec{
+		unmarshal: func(data []byte, v any) error {
+			calls = append(calls, "c2")
+			return nil
+		},
+	}
+	g := NewCodecGroup(c1, c2)
+	if g.Selected() != nil {
+		t.Fatal("expected no selected codec before first Unmarshal")
+	}
+	var out any
+	if err := g.Unmarshal([]byte("{}"), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.Selected() == nil {
+		t.Fatal("expected selected codec after successful Unmarshal")
+	}
+	calls = nil
+	if err := g.Unmarshal([]byte("{}"), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 1 || calls[0] != "c2" {
+		t.Fatalf("expected only c2 to be tried, got %v", calls)
+	}
+}
+
+func TestFallbackStickyDoesNotFallBack(t *testing.T) {
+	// c1 fails only on the first call so that c2 gets selected; c2 fails afterwards.
+	first, fail := true, false
+	c1 := testCodec{unmarshal: func(data []byte, v any) error {
+		if first {
+			first = false
+			return errors.New("nope1")
+		}
+		return nil
+	}}
+	c2 := testCodec{unmarshal: func(data []byte, v any) error {
+		if fail {
+			return errors.New("nope2")
+		}
+		return nil
+	}}
+	g := NewCodecGroup(c1, c2).WithSticky()
+	var out any
+	if err := g.Unmarshal([]byte("{}"), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fail = true
+	if err := g.Unmarshal([]byte("{}"), &out); err == nil {
+		t.Fatal("expected sticky group to fail without falling back to c1")
+	}
+}
@@ -0,0 +1,83 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrLimitExceeded indicates that a document exceeded a configured decoding limit
+// such as maximum nesting depth or input size.
+var ErrLimitExceeded = errors.New("codec: decode limit exceeded")
+
+type limits struct {
+	maxDepth int
+	maxSize  int64
+}
+
+// LimitOption configures decoding limits for JsonCodecWithLimits.
+type LimitOption func(*limits)
+
+// WithMaxDepth limits the nesting depth of objects and arrays.
+// A non-positive value disables the limit.
+func WithMaxDepth(n int) LimitOption { return func(l *limits) { l.maxDepth = n } }
+
+// WithMaxSize limits the size of the input document in bytes.
+// A non-positive value disables the limit.
+func WithMaxSize(n int64) LimitOption { return func(l *limits) { l.maxSize = n } }
+
+// JsonCodecWithLimits creates a JSON codec that rejects documents exceeding the
+// configured limits with ErrLimitExceeded before decoding them. Use it when
+// configuration may come from untrusted remote sources.
+func JsonCodecWithLimits(opts ...LimitOption) Codec {
+	l := &limits{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return &codec{
+		encoder: json.Marshal,
+		decoder: func(data []byte, val any) error {
+			if l.maxSize > 0 && int64(len(data)) > l.maxSize {
+				return fmt.Errorf("%w: size %d exceeds limit %d", ErrLimitExceeded, len(data), l.maxSize)
+			}
+			if l.maxDepth > 0 {
+				if err := checkJSONDepth(data, l.maxDepth); err != nil {
+					return err
+				}
+			}
+			return jsonUnmarshal(data, val)
+		},
+	}
+}
+
+// checkJSONDepth scans data and fails once objects/arrays nest deeper than maxDepth.
+// It does not validate the document; syntax errors are left to the decoder.
+func checkJSONDepth(data []byte, maxDepth int) error {
+	depth := 0
+	inString, escaped := false, false
+	for i, b := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+		switch b {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return fmt.Errorf("%w: depth exceeds limit %d at offset %d", ErrLimitExceeded, maxDepth, i)
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package codec
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJsonCodecWithLimits_Depth(t *testing.T) {
+	c := JsonCodecWithLimits(WithMaxDepth(3))
+	var out any
+	if err := c.Unmarshal([]byte(`{"a":{"b":["[[[[ not nested ]]]]"]}}`), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deep := strings.Repeat("[", 4) + strings.Repeat("]", 4)
+	if err := c.Unmarshal([]byte(deep), &out); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+func TestJsonCodecWithLimits_Size(t *testing.T) {
+	c := JsonCodecWithLimits(WithMaxSize(8))
+	var out any
+	if err := c.Unmarshal([]byte(`{"a":1}`), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Unmarshal([]byte(`{"a":"long"}`), &out); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("expected ErrLimitExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownCodec indicates that no codec is registered under the requested name or MIME type.
+var ErrUnknownCodec = errors.New("unknown codec")
+
+// Registry maps names and MIME types to codecs, so a format can be chosen from
+// a flag value or a Content-Type header. A Registry is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	byName map[string]Codec
+	byMime map[string]Codec
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[string]Codec), byMime: make(map[string]Codec)}
+}
+
+// Register makes a codec available to Lookup under a name (e.g. "yaml") and
+// any number of MIME types (e.g. "application/yaml"). Names and MIME types are
+// case-insensitive. Like provider registration it is intended for init functions
+// and panics if the codec is nil or the name or a MIME type is already
+// registered; nothing is registered in that case.
+func (r *Registry) Register(name string, mimeTypes []string, c Codec) {
+	if c == nil {
+		panic("codec: Register codec is nil")
+	}
+	name = strings.ToLower(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.byName[name]; dup {
+		panic("codec: Register called twice for name " + name)
+	}
+	normalized := make([]string, 0, len(mimeTypes))
+	for _, mt := range mimeTypes {
+		mt = normalizeMime(mt)
+		_, dup := r.byMime[mt]
+		for _, seen := range normalized {
+			dup = dup || seen == mt
+		}
+		if dup {
+			panic("codec: Register called twice for mime type " + mt)
+		}
+		normalized = append(normalized, mt)
+	}
+	for _, mt := range normalized {
+		r.byMime[mt] = c
+	}
+	r.byName[name] = c
+}
+
+// Lookup resolves a codec by registered name (e.g. from a "--format" flag) or
+// by MIME type. MIME parameters are ignored, so an HTTP Content-Type header
+// such as "application/json; charset=utf-8" can be passed as-is.
+func (r *Registry) Lookup(nameOrMime string) (Codec, error) {
+	key := strings.ToLower(strings.TrimSpace(nameOrMime))
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.byName[key]; ok {
+		return c, nil
+	}
+	if c, ok := r.byMime[normalizeMime(key)]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownCodec, nameOrMime)
+}
+
+// Names returns the registered codec names in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.byName))
+	for name := range r.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultRegistry is the registry used by the package-level Register and
+// Lookup. The json and string codecs are registered in it.
+var DefaultRegistry = NewRegistry()
+
+func init() {
+	DefaultRegistry.Register("json", []string{"application/json", "text/json"}, JsonCodec())
+	DefaultRegistry.Register("string", []string{"text/plain"}, StringCodec())
+}
+
+// Register makes a codec available in DefaultRegistry.
+func Register(name string, mimeTypes []string, c Codec) {
+	DefaultRegistry.Register(name, mimeTypes, c)
+}
+
+// Lookup resolves a codec in DefaultRegistry.
+func Lookup(nameOrMime string) (Codec, error) {
+	return DefaultRegistry.Lookup(nameOrMime)
+}
+
+func normalizeMime(mt string) string {
+	if parsed, _, err := mime.ParseMediaType(mt); err == nil {
+		return parsed
+	}
+	return strings.ToLower(strings.TrimSpace(mt))
+}
@@ -0,0 +1,55 @@
+package codec
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLookupBuiltins(t *testing.T) {
+	for _, key := range []string{"json", "JSON", "application/json", "application/json; charset=utf-8", "text/plain"} {
+		if _, err := Lookup(key); err != nil {
+			t.Fatalf("Lookup(%q) error: %v", key, err)
+		}
+	}
+}
+
+func TestRegisterAndLookupCustom(t *testing.T) {
+	c := NewCodec(
+		func(val any) ([]byte, error) { return []byte("x"), nil },
+		func(data []byte, val any) error { return nil },
+	)
+	r := NewRegistry()
+	r.Register("x-test", []string{"application/x-test"}, c)
+	got, err := r.Lookup("Application/X-Test")
+	if err != nil {
+		t.Fatalf("Lookup error: %v", err)
+	}
+	if got != c {
+		t.Fatalf("Lookup returned a different codec")
+	}
+}
+
+func TestLookupUnknown(t *testing.T) {
+	if _, err := Lookup("toml"); !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("expected ErrUnknownCodec, got %v", err)
+	}
+}
+
+func TestRegistryDuplicateMimeRegistersNothing(t *testing.T) {
+	r := NewRegistry()
+	r.Register("json", []string{"application/json"}, JsonCodec())
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic on duplicate mime type")
+			}
+		}()
+		r.Register("yaml", []string{"application/yaml", "Application/JSON"}, StringCodec())
+	}()
+	if _, err := r.Lookup("application/yaml"); !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("partial registration left behind: %v", err)
+	}
+	if names := r.Names(); len(names) != 1 || names[0] != "json" {
+		t.Fatalf("unexpected names %v", names)
+	}
+}
@@ -0,0 +1,166 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// StreamCodec decodes directly from a reader, so large documents are not
+// buffered in full before decoding.
+type StreamCodec interface {
+	// Decode reads a single document from r into val, which must be a pointer.
+	Decode(r io.Reader, val any) error
+}
+
+// StreamCodecFunc is a function adapter that implements the StreamCodec interface.
+type StreamCodecFunc func(r io.Reader, val any) error
+
+// Decode implements the StreamCodec interface by calling the function itself.
+func (f StreamCodecFunc) Decode(r io.Reader, val any) error {
+	return f(r, val)
+}
+
+// JsonStreamCodec creates a StreamCodec for JSON using json.Decoder. The
+// input must hold exactly one JSON value; trailing data is an error. Like
+// JsonCodec, syntax and type errors are returned as *DecodeError. Positions
+// are tracked in constant memory; for type errors they are only known when r
+// is an io.Seeker (such as *os.File), since finding them means reading the
+// input again.
+func JsonStreamCodec() StreamCodec {
+	return StreamCodecFunc(func(r io.Reader, val any) error {
+		lines := newLineIndex(r)
+		dec := json.NewDecoder(lines)
+		if err := dec.Decode(val); err != nil {
+			return lines.decodeError(err)
+		}
+		if _, err := dec.Token(); err != io.EOF {
+			if err == nil {
+				err = errors.New("unexpected data after top-level value")
+			}
+			return lines.decodeError(fmt.Errorf("invalid trailing data: %w", err))
+		}
+		return nil
+	})
+}
+
+// maxLineRead caps a single Read from the underlying reader, which bounds the
+// data held back by lineIndex between calls.
+const maxLineRead = 4 << 10
+
+// lineIndex tracks line positions while reading so that decode errors can
+// report line and column in constant memory. Each Read ends at the first
+// newline, so the decoder, which scans every chunk as it arrives, reports
+// syntax errors on the current or the just-finished line, which is all that
+// is tracked. Positions further back (type errors are detected only after
+// the whole value was read) are recovered by rescanning the input when the
+// reader is an io.Seeker.
+type lineIndex struct {
+	r       io.Reader
+	offset  int64
+	lines   int   // newlines read so far
+	lastNL  int64 // offset of the last newline read, or -1
+	prevNL  int64 // offset of the newline before lastNL, or -1
+	start   int64 // seek position of the input, or -1 if it cannot be rescanned
+	err     error
+	buf     [maxLineRead]byte
+	pending []byte
+}
+
+func newLineIndex(r io.Reader) *lineIndex {
+	l := &lineIndex{r: r, lastNL: -1, prevNL: -1, start: -1}
+	if seeker, ok := r.(io.Seeker); ok {
+		if pos, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			l.start = pos
+		}
+	}
+	return l
+}
+
+func (l *lineIndex) Read(p []byte) (int, error) {
+	if len(l.pending) == 0 && l.err == nil {
+		var n int
+		n, l.err = l.r.Read(l.buf[:min(len(p), maxLineRead)])
+		l.pending = l.buf[:n]
+	}
+	n := copy(p, l.pending)
+	if i := bytes.IndexByte(p[:n], '\n'); i >= 0 {
+		n = i + 1
+		l.lines++
+		l.prevNL, l.lastNL = l.lastNL, l.offset+int64(i)
+	}
+	l.pending = l.pending[n:]
+	l.offset += int64(n)
+	if len(l.pending) > 0 {
+		// The error, if any, is returned once the held-back data is delivered.
+		return n, nil
+	}
+	return n, l.err
+}
+
+// position mirrors LineColumn for the bytes read so far.
+func (l *lineIndex) position(offset int64) (line, column int) {
+	switch {
+	case offset < 0 || offset > l.offset:
+		return 0, 0
+	case offset > l.lastNL:
+		return l.lines + 1, int(offset - l.lastNL)
+	case offset > l.prevNL:
+		return l.lines, int(offset - l.prevNL)
+	}
+	return l.rescan(offset)
+}
+
+// rescan counts the lines before offset by reading the input again from its
+// start position.
+func (l *lineIndex) rescan(offset int64) (line, column int) {
+	seeker, ok := l.r.(io.Seeker)
+	if !ok || l.start < 0 {
+		return 0, 0
+	}
+	if _, err := seeker.Seek(l.start, io.SeekStart); err != nil {
+		return 0, 0
+	}
+	lastNL := int64(-1)
+	var pos int64
+	var buf [maxLineRead]byte
+	for pos < offset {
+		n, err := l.r.Read(buf[:min(int64(len(buf)), offset-pos)])
+		for i, b := range buf[:n] {
+			if b == '\n' {
+				line++
+				lastNL = pos + int64(i)
+			}
+		}
+		pos += int64(n)
+		if err != nil && pos < offset {
+			return 0, 0
+		}
+	}
+	return line + 1, int(offset - lastNL)
+}
+
+func (l *lineIndex) decodeError(err error) error {
+	path, offset := "", int64(-1)
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = lastByte(syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		path, offset = typeErr.Field, lastByte(typeErr.Offset)
+	default:
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			offset = l.offset
+		} else {
+			return err
+		}
+	}
+	line, column := l.position(offset)
+	if line == 0 {
+		offset = -1
+	}
+	return &DecodeError{Path: path, Offset: offset, Line: line, Column: column, Err: err}
+}
@@ -0,0 +1,102 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestJsonStreamCodec(t *testing.T) {
+	var v struct {
+		Addr string `json:"addr"`
+	}
+	if err := JsonStreamCodec().Decode(strings.NewReader(`{"addr":":80"}`+"\n"), &v); err != nil || v.Addr != ":80" {
+		t.Fatalf("Decode = %+v, %v", v, err)
+	}
+	err := JsonStreamCodec().Decode(strings.NewReader(`{"addr":":80"} {"addr":":81"}`), &v)
+	if err == nil {
+		t.Fatal("expected error for trailing data")
+	}
+}
+
+func TestJsonStreamCodec_ErrorPosition(t *testing.T) {
+	var v struct {
+		Port int `json:"port"`
+	}
+	input := "{\n  \"port\": \"x\"\n}"
+	err := JsonStreamCodec().Decode(strings.NewReader(input), &v)
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("expected DecodeError, got %v", err)
+	}
+	// Positions must match the buffered codec.
+	want := new(DecodeError)
+	if !errors.As(JsonCodec().Unmarshal([]byte(input), &v), &want) {
+		t.Fatal("expected DecodeError from JsonCodec")
+	}
+	if de.Path != "port" || de.Line != want.Line || de.Column != want.Column {
+		t.Fatalf("got %s:%d:%d, want port:%d:%d", de.Path, de.Line, de.Column, want.Line, want.Column)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("underlying error not reachable: %v", err)
+	}
+}
+
+// onlyReader hides io.Seeker so that positions come from line tracking alone.
+type onlyReader struct{ r io.Reader }
+
+func (o onlyReader) Read(p []byte) (int, error) { return o.r.Read(p) }
+
+func TestJsonStreamCodec_SyntaxErrorPositions(t *testing.T) {
+	var rows []string
+	for i := 0; i < 2000; i++ {
+		rows = append(rows, fmt.Sprintf(`  "k%d": %d,`, i, i))
+	}
+	for _, input := range []string{
+		"{\n" + strings.Join(rows, "\n") + "\n  \"bad\": tru\n}",
+		"{\n" + strings.Join(rows[:1000], "\n") + "\n  \"bad\": [1 2],\n" + strings.Join(rows[1000:], "\n") + "\n}",
+		"{\"a\": 1,\n\n  \"b\" 2}",
+	} {
+		var v map[string]any
+		var de *DecodeError
+		if !errors.As(JsonStreamCodec().Decode(onlyReader{strings.NewReader(input)}, &v), &de) {
+			t.Fatalf("expected DecodeError")
+		}
+		want := new(DecodeError)
+		if !errors.As(JsonCodec().Unmarshal([]byte(input), &v), &want) {
+			t.Fatal("expected DecodeError from JsonCodec")
+		}
+		if de.Line != want.Line || de.Column != want.Column {
+			t.Fatalf("got %d:%d, want %d:%d (%v)", de.Line, de.Column, want.Line, want.Column, de)
+		}
+	}
+}
+
+func TestJsonStreamCodec_TypeErrorPositionBySeeking(t *testing.T) {
+	var rows []string
+	for i := 0; i < 2000; i++ {
+		rows = append(rows, fmt.Sprintf(`  "k%d": %d,`, i, i))
+	}
+	input := "{\n  \"port\": \"x\",\n" + strings.Join(rows, "\n") + "\n  \"end\": 0\n}"
+	var v struct {
+		Port int `json:"port"`
+	}
+	var de, want *DecodeError
+	if !errors.As(JsonStreamCodec().Decode(strings.NewReader(input), &v), &de) {
+		t.Fatal("expected DecodeError")
+	}
+	if !errors.As(JsonCodec().Unmarshal([]byte(input), &v), &want) {
+		t.Fatal("expected DecodeError from JsonCodec")
+	}
+	if de.Line != want.Line || de.Column != want.Column || de.Line != 2 {
+		t.Fatalf("got %d:%d, want %d:%d", de.Line, de.Column, want.Line, want.Column)
+	}
+	// Without seeking the position is unknown rather than wrong.
+	if !errors.As(JsonStreamCodec().Decode(onlyReader{strings.NewReader(input)}, &v), &de) || de.Line != 0 {
+		t.Fatalf("expected unknown position, got %v", de)
+	}
+}
@@ -39,3 +39,34 @@ func FillWithContext(ctx context.Context, provider provider.Provider, codec code
 func Fill(provider provider.Provider, codec codec.Codec, config any) error {
 	return FillWithContext(context.Background(), provider, codec, config)
 }
+
+// LoadAt reads the configuration as it was at the given version and unmarshal it into the provided struct.
+// The provider must implement provider.VersionedProvider; otherwise provider.ErrVersionUnsupported is returned.
+// This lets incident responders reconstruct exactly what configuration a service had at a given moment.
+func LoadAt[T any](ctx context.Context, source provider.Provider, codec codec.Codec, version string) (*T, error) {
+	data, err := provider.ReadAt(ctx, source, version)
+	if err != nil {
+		return nil, err
+	}
+	var config T
+	err = codec.Unmarshal(data, &config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// LoadStream decodes configuration straight from the provider's stream, so very large documents
+// (e.g. generated routing tables) are not buffered in memory before decoding.
+func LoadStream[T any](ctx context.Context, provider provider.StreamProvider, codec codec.StreamCodec) (*T, error) {
+	r, err := provider.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	var config T
+	if err = codec.Decode(r, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
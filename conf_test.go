@@ -2,12 +2,14 @@ package confstore
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/go-sphere/confstore/codec"
 	"github.com/go-sphere/confstore/provider"
+	"github.com/go-sphere/confstore/provider/file"
 )
 
 type appConf struct {
@@ -32,3 +34,44 @@ func TestLoadWithFileJSON(t *testing.T) {
 		t.Fatalf("unexpected config: %+v", cfg)
 	}
 }
+
+type versionedProvider struct {
+	provider.Provider
+	versions map[string]string
+}
+
+func (v versionedProvider) ReadAt(ctx context.Context, version string) ([]byte, error) {
+	return []byte(v.versions[version]), nil
+}
+
+func TestLoadAt(t *testing.T) {
+	p := versionedProvider{
+		Provider: staticProvider(`{"addr":"new"}`),
+		versions: map[string]string{"42": `{"addr":"old","mode":"dev"}`},
+	}
+	cfg, err := LoadAt[appConf](context.Background(), p, codec.JsonCodec(), "42")
+	if err != nil {
+		t.Fatalf("LoadAt error: %v", err)
+	}
+	if cfg.Addr != "old" || cfg.Mode != "dev" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	_, err = LoadAt[appConf](context.Background(), staticProvider(`{}`), codec.JsonCodec(), "42")
+	if !errors.Is(err, provider.ErrVersionUnsupported) {
+		t.Fatalf("expected ErrVersionUnsupported, got %v", err)
+	}
+}
+
+func TestLoadStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(path, []byte("\xEF\xBB\xBF"+`{"addr":":80","mode":"prod"}`), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	conf, err := LoadStream[appConf](context.Background(), file.New(path, file.WithTrimBOM()), codec.JsonStreamCodec())
+	if err != nil {
+		t.Fatalf("LoadStream error: %v", err)
+	}
+	if conf.Addr != ":80" || conf.Mode != "prod" {
+		t.Fatalf("unexpected config: %+v", conf)
+	}
+}
@@ -0,0 +1,169 @@
+package confstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NewFS exposes a decoded configuration as a read-only fs.FS, so components
+// that insist on reading config files can be pointed at in-memory state.
+//
+// The config is converted through its JSON representation: objects become
+// directories, array elements become entries named by index ("0", "1", ...),
+// and scalars become files holding their text form (strings unquoted, null as
+// an empty file). For example, {"db":{"dsn":"x"}} exposes the file "db/dsn".
+// The view is a snapshot; later changes to config are not reflected.
+func NewFS(config any) (fs.FS, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("confstore: fs encode config: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err = dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("confstore: fs decode config: %w", err)
+	}
+	root, err := newFSNode(".", tree, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !root.isDir {
+		return nil, fmt.Errorf("confstore: fs root must be an object or array, got %s", data)
+	}
+	return &configFS{root: root}, nil
+}
+
+type fsNode struct {
+	name     string
+	isDir    bool
+	data     []byte
+	children map[string]*fsNode
+	entries  []fs.DirEntry // sorted by name
+	modTime  time.Time
+}
+
+func newFSNode(name string, v any, modTime time.Time) (*fsNode, error) {
+	n := &fsNode{name: name, modTime: modTime}
+	switch val := v.(type) {
+	case map[string]any:
+		n.isDir = true
+		n.children = make(map[string]*fsNode, len(val))
+		for k, child := range val {
+			if k == "" || k == "." || k == ".." || strings.Contains(k, "/") {
+				return nil, fmt.Errorf("confstore: fs key %q cannot be used as a file name", k)
+			}
+			c, err := newFSNode(k, child, modTime)
+			if err != nil {
+				return nil, err
+			}
+			n.children[k] = c
+		}
+	case []any:
+		n.isDir = true
+		n.children = make(map[string]*fsNode, len(val))
+		for i, child := range val {
+			k := fmt.Sprint(i)
+			c, err := newFSNode(k, child, modTime)
+			if err != nil {
+				return nil, err
+			}
+			n.children[k] = c
+		}
+	case nil:
+		// null is exposed as an empty file.
+	case string:
+		n.data = []byte(val)
+	default:
+		n.data = []byte(fmt.Sprint(val))
+	}
+	for _, c := range n.children {
+		n.entries = append(n.entries, fsInfo{c})
+	}
+	sort.Slice(n.entries, func(i, j int) bool { return n.entries[i].Name() < n.entries[j].Name() })
+	return n, nil
+}
+
+type configFS struct {
+	root *fsNode
+}
+
+// Open implements fs.FS.
+func (c *configFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	n := c.root
+	if name != "." {
+		for _, part := range strings.Split(name, "/") {
+			child, ok := n.children[part]
+			if !ok {
+				return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+			}
+			n = child
+		}
+	}
+	if n.isDir {
+		return &fsDir{node: n, path: name}, nil
+	}
+	return &fsFile{node: n, Reader: bytes.NewReader(n.data)}, nil
+}
+
+// fsInfo implements fs.FileInfo and fs.DirEntry for a node.
+type fsInfo struct{ n *fsNode }
+
+func (i fsInfo) Name() string               { return i.n.name }
+func (i fsInfo) Size() int64                { return int64(len(i.n.data)) }
+func (i fsInfo) ModTime() time.Time         { return i.n.modTime }
+func (i fsInfo) IsDir() bool                { return i.n.isDir }
+func (i fsInfo) Sys() any                   { return nil }
+func (i fsInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i fsInfo) Info() (fs.FileInfo, error) { return i, nil }
+func (i fsInfo) Mode() fs.FileMode {
+	if i.n.isDir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+type fsFile struct {
+	node *fsNode
+	*bytes.Reader
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) { return fsInfo{f.node}, nil }
+func (f *fsFile) Close() error               { return nil }
+
+type fsDir struct {
+	node   *fsNode
+	path   string
+	offset int
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) { return fsInfo{d.node}, nil }
+func (d *fsDir) Close() error               { return nil }
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *fsDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	remaining := d.node.entries[d.offset:]
+	if count > 0 {
+		if len(remaining) == 0 {
+			return nil, io.EOF
+		}
+		if count < len(remaining) {
+			remaining = remaining[:count]
+		}
+	}
+	d.offset += len(remaining)
+	return append([]fs.DirEntry(nil), remaining...), nil
+}
@@ -0,0 +1,49 @@
+package confstore
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewFS(t *testing.T) {
+	config := map[string]any{
+		"addr": "127.0.0.1:8080",
+		"db":   map[string]any{"dsn": "postgres://x", "pool": 10},
+		"tags": []string{"a", "b"},
+		"tls":  nil,
+	}
+	fsys, err := NewFS(config)
+	if err != nil {
+		t.Fatalf("NewFS error: %v", err)
+	}
+	if err = fstest.TestFS(fsys, "addr", "db/dsn", "db/pool", "tags/0", "tags/1", "tls"); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"addr": "127.0.0.1:8080", "db/pool": "10", "tags/1": "b", "tls": ""} {
+		got, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			t.Fatalf("ReadFile(%q) error: %v", name, err)
+		}
+		if string(got) != want {
+			t.Fatalf("ReadFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNewFS_StructFollowsJSONTags(t *testing.T) {
+	fsys, err := NewFS(appConf{Addr: ":80", Mode: "prod"})
+	if err != nil {
+		t.Fatalf("NewFS error: %v", err)
+	}
+	got, err := fs.ReadFile(fsys, "mode")
+	if err != nil || string(got) != "prod" {
+		t.Fatalf("ReadFile(mode) = %q, %v", got, err)
+	}
+}
+
+func TestNewFS_ScalarRoot(t *testing.T) {
+	if _, err := NewFS("just a string"); err == nil {
+		t.Fatal("expected error for scalar root")
+	}
+}
@@ -0,0 +1,156 @@
+// Package confstoretest provides test doubles and golden-file helpers for
+// code that depends on confstore providers and codecs.
+package confstoretest
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/confstore/codec"
+	"github.com/go-sphere/confstore/internal/wait"
+	"github.com/go-sphere/confstore/provider"
+)
+
+// UpdateEnv is the environment variable that makes AssertGolden rewrite
+// golden files instead of comparing against them, e.g.
+// CONFSTORE_UPDATE_GOLDEN=1 go test ./...
+const UpdateEnv = "CONFSTORE_UPDATE_GOLDEN"
+
+// Step is one scripted response of a Fake provider.
+type Step struct {
+	Data  []byte
+	Err   error
+	Delay time.Duration // wait before responding; aborted if the context is done
+}
+
+// Fake is a provider.Provider that replays a script of responses, one per
+// Read. Once the script is exhausted the last step repeats. It is safe for
+// concurrent use.
+type Fake struct {
+	mu    sync.Mutex
+	steps []Step
+	calls int
+}
+
+// NewFake returns a Fake that replays steps in order. With no steps, Read
+// returns empty data.
+func NewFake(steps ...Step) *Fake {
+	return &Fake{steps: steps}
+}
+
+// Read implements provider.Provider.
+func (f *Fake) Read(ctx context.Context) ([]byte, error) {
+	f.mu.Lock()
+	var step Step
+	if len(f.steps) > 0 {
+		step = f.steps[min(f.calls, len(f.steps)-1)]
+	}
+	f.calls++
+	f.mu.Unlock()
+	if step.Delay > 0 {
+		if err := wait.Sleep(ctx, step.Delay); err != nil {
+			return nil, err
+		}
+	}
+	if step.Err != nil {
+		return nil, step.Err
+	}
+	return bytes.Clone(step.Data), nil
+}
+
+// Push appends steps to the script.
+func (f *Fake) Push(steps ...Step) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.steps = append(f.steps, steps...)
+}
+
+// Calls returns the number of Read calls so far.
+func (f *Fake) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// Call records one Read made through a Recorder.
+type Call struct {
+	Start    time.Time
+	Duration time.Duration
+	Data     []byte
+	Err      error
+}
+
+// Recorder wraps a provider and records every Read. It is safe for concurrent use.
+type Recorder struct {
+	provider provider.Provider
+	mu       sync.Mutex
+	calls    []Call
+}
+
+// Record wraps p in a Recorder.
+func Record(p provider.Provider) *Recorder {
+	return &Recorder{provider: p}
+}
+
+// Read implements provider.Provider by delegating to the wrapped provider.
+func (r *Recorder) Read(ctx context.Context) ([]byte, error) {
+	start := time.Now()
+	data, err := r.provider.Read(ctx)
+	r.mu.Lock()
+	r.calls = append(r.calls, Call{Start: start, Duration: time.Since(start), Data: bytes.Clone(data), Err: err})
+	r.mu.Unlock()
+	return data, err
+}
+
+// Calls returns the recorded calls in completion order.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// LoadGolden decodes the file at path into a new T, failing the test on error.
+func LoadGolden[T any](t testing.TB, path string, c codec.Codec) *T {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("confstoretest: read golden file: %v", err)
+	}
+	var config T
+	if err = c.Unmarshal(data, &config); err != nil {
+		t.Fatalf("confstoretest: decode golden file %s: %v", path, err)
+	}
+	return &config
+}
+
+// AssertGolden encodes got with c and compares it with the golden file at
+// path, failing the test on mismatch. When UpdateEnv is set, the golden file
+// is (re)written instead.
+func AssertGolden(t testing.TB, path string, c codec.Codec, got any) {
+	t.Helper()
+	data, err := c.Marshal(got)
+	if err != nil {
+		t.Fatalf("confstoretest: encode value: %v", err)
+	}
+	if os.Getenv(UpdateEnv) != "" {
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+			err = os.WriteFile(path, data, 0o644)
+		}
+		if err != nil {
+			t.Fatalf("confstoretest: update golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("confstoretest: read golden file (set %s=1 to create it): %v", UpdateEnv, err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(data), bytes.TrimSpace(want)) {
+		t.Fatalf("confstoretest: %s does not match (set %s=1 to update):\n got: %s\nwant: %s", path, UpdateEnv, data, want)
+	}
+}
@@ -0,0 +1,70 @@
+package confstoretest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/confstore/codec"
+)
+
+type appConf struct {
+	Addr string `json:"addr"`
+	Mode string `json:"mode"`
+}
+
+func TestFake_Script(t *testing.T) {
+	boom := errors.New("boom")
+	f := NewFake(Step{Data: []byte("v1")}, Step{Err: boom}, Step{Data: []byte("v2")})
+	ctx := context.Background()
+
+	if got, err := f.Read(ctx); err != nil || string(got) != "v1" {
+		t.Fatalf("step 1 = %q, %v", got, err)
+	}
+	if _, err := f.Read(ctx); !errors.Is(err, boom) {
+		t.Fatalf("step 2 err = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if got, err := f.Read(ctx); err != nil || string(got) != "v2" {
+			t.Fatalf("last step should repeat, got %q, %v", got, err)
+		}
+	}
+	if f.Calls() != 4 {
+		t.Fatalf("Calls = %d, want 4", f.Calls())
+	}
+}
+
+func TestFake_DelayHonorsContext(t *testing.T) {
+	f := NewFake(Step{Data: []byte("late"), Delay: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := f.Read(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r := Record(NewFake(Step{Data: []byte("a")}, Step{Err: errors.New("down")}))
+	_, _ = r.Read(context.Background())
+	_, _ = r.Read(context.Background())
+	calls := r.Calls()
+	if len(calls) != 2 || string(calls[0].Data) != "a" || calls[1].Err == nil {
+		t.Fatalf("unexpected calls: %+v", calls)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	conf := LoadGolden[appConf](t, filepath.Join("testdata", "app.json"), codec.JsonCodec())
+	if conf.Addr != ":8080" || conf.Mode != "prod" {
+		t.Fatalf("unexpected config: %+v", conf)
+	}
+	AssertGolden(t, filepath.Join("testdata", "app.json"), codec.JsonCodec(), conf)
+
+	t.Setenv(UpdateEnv, "1")
+	path := filepath.Join(t.TempDir(), "out", "app.json")
+	AssertGolden(t, path, codec.JsonCodec(), conf)
+	t.Setenv(UpdateEnv, "")
+	AssertGolden(t, path, codec.JsonCodec(), conf)
+}
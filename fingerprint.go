@@ -0,0 +1,46 @@
+package confstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Fingerprint returns a stable hash of config for detecting drift between
+// instances: two configs with the same effective content have the same
+// fingerprint regardless of how their source documents were formatted or
+// ordered.
+//
+// The config is canonicalized through its JSON representation with object
+// keys sorted. Fields tagged secret:"true" are excluded, so the fingerprint
+// can be exported as a metric label or logged without leaking secrets. As a
+// consequence rotating a secret does not change it, so equal fingerprints do
+// not mean equal configs and must not be used to skip reloads. The result
+// has the form "sha256:<hex>".
+func Fingerprint(config any) (string, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("confstore: fingerprint encode config: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err = dec.Decode(&tree); err != nil {
+		return "", fmt.Errorf("confstore: fingerprint decode config: %w", err)
+	}
+	if config != nil {
+		tree = filterTree(reflect.TypeOf(config), tree, "", func(field reflect.StructField, _ string, v any) (any, bool) {
+			return v, !isSecret(field)
+		})
+	}
+	// encoding/json writes map keys in sorted order.
+	canonical, err := json.Marshal(tree)
+	if err != nil {
+		return "", fmt.Errorf("confstore: fingerprint encode canonical form: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
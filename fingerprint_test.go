@@ -0,0 +1,40 @@
+package confstore
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type fingerprintConf struct {
+	Addr     string            `json:"addr"`
+	Password string            `json:"password" secret:"true"`
+	Labels   map[string]string `json:"labels"`
+	Large    int64             `json:"large"`
+}
+
+func TestFingerprint(t *testing.T) {
+	var a, b fingerprintConf
+	if err := json.Unmarshal([]byte(`{"addr":":80","labels":{"x":"1","y":"2"},"password":"p1","large":9007199254740993}`), &a); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"large":9007199254740993, "password":"p2","labels":{"y":"2","x":"1"},"addr":":80"}`), &b); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	fa, err := Fingerprint(&a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fb, _ := Fingerprint(b)
+	if fa != fb {
+		t.Fatalf("equivalent configs differ: %s vs %s", fa, fb)
+	}
+	if !strings.HasPrefix(fa, "sha256:") || len(fa) != len("sha256:")+64 {
+		t.Fatalf("unexpected format %q", fa)
+	}
+
+	b.Large++
+	if fc, _ := Fingerprint(b); fc == fa {
+		t.Fatal("fingerprint ignores a changed value")
+	}
+}
@@ -0,0 +1,216 @@
+package confstore
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrRefCycle indicates that ${ref:...} references form a cycle.
+var ErrRefCycle = errors.New("confstore: reference cycle")
+
+const (
+	refOpen    = "${ref:"
+	refEscaped = "$" + refOpen
+)
+
+// Interpolate resolves ${ref:path} references between the values of a
+// decoded configuration, so a value such as a base URL can be reused by
+// several fields:
+//
+//	{"baseURL": "https://api.example.com", "users": "${ref:baseURL}/users"}
+//
+// Paths use json names joined with dots, slice elements are addressed by
+// index and map values by key ("servers[0].url", "tenants.acme.dsn"), as in
+// the paths reported by Check and Resolve. Only
+// scalar values (strings, booleans, numbers and types implementing
+// fmt.Stringer or encoding.TextMarshaler) can be referenced. References may
+// be nested; cycles fail with ErrRefCycle and unknown paths with
+// ErrRefNotFound. "$${ref:" produces a literal "${ref:".
+//
+// Unlike provider.ExpandEnv, which rewrites raw bytes before decoding,
+// Interpolate works on the decoded value and only ever changes string fields.
+func Interpolate(config any) error {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("confstore: interpolate target must be a non-nil pointer, got %T", config)
+	}
+	in := &interpolator{values: make(map[string]reflect.Value), resolved: make(map[string]string)}
+	walkValues(v.Elem(), "", func(path string, v reflect.Value) {
+		in.values[path] = v
+		if v.Kind() == reflect.String && strings.Contains(v.String(), refOpen) {
+			in.order = append(in.order, path)
+		}
+	})
+	var errs []error
+	for _, path := range in.order {
+		if _, err := in.resolve(path, nil); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	walkValues(v.Elem(), "", func(path string, v reflect.Value) {
+		if s, ok := in.resolved[path]; ok && v.Kind() == reflect.String && v.CanSet() {
+			v.SetString(s)
+		}
+	})
+	return nil
+}
+
+type interpolator struct {
+	values   map[string]reflect.Value
+	order    []string
+	resolved map[string]string
+}
+
+// resolve returns the interpolated text of the value at path. stack holds the
+// paths currently being resolved, for cycle detection.
+func (in *interpolator) resolve(path string, stack []string) (string, error) {
+	if s, ok := in.resolved[path]; ok {
+		return s, nil
+	}
+	for i, p := range stack {
+		if p == path {
+			return "", fmt.Errorf("%w: %s", ErrRefCycle, strings.Join(append(stack[i:], path), " -> "))
+		}
+	}
+	v, ok := in.values[path]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrRefNotFound, path)
+	}
+	if v.Kind() != reflect.String {
+		return scalarText(v)
+	}
+	stack = append(stack, path)
+	src := v.String()
+	var b strings.Builder
+	for {
+		i := strings.Index(src, refOpen)
+		if i < 0 {
+			b.WriteString(src)
+			break
+		}
+		if i > 0 && src[i-1] == '$' {
+			// Escaped: "$${ref:" is a literal "${ref:".
+			b.WriteString(src[:i-1] + refOpen)
+			src = src[i+len(refOpen):]
+			continue
+		}
+		end := strings.IndexByte(src[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("confstore: unterminated reference in %q", v.String())
+		}
+		ref := src[i+len(refOpen) : i+end]
+		s, err := in.resolve(ref, stack)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(src[:i] + s)
+		src = src[i+end+1:]
+	}
+	in.resolved[path] = b.String()
+	return b.String(), nil
+}
+
+// scalarText formats a referenced non-string value.
+func scalarText(v reflect.Value) (string, error) {
+	if v.CanInterface() {
+		switch x := v.Interface().(type) {
+		case encoding.TextMarshaler:
+			text, err := x.MarshalText()
+			return string(text), err
+		case fmt.Stringer:
+			return x.String(), nil
+		}
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("confstore: cannot reference non-scalar %s", v.Type())
+}
+
+// walkValues calls visit for every value reachable from v, keyed by its
+// json path ("servers[0].url"). Map values and the dynamic values of
+// interfaces are visited through a copy that is stored back afterwards, so
+// visit may modify them.
+func walkValues(v reflect.Value, path string, visit func(path string, v reflect.Value)) {
+	switch {
+	case v.Kind() == reflect.Pointer && !v.IsNil():
+		walkValues(v.Elem(), path, visit)
+		return
+	case v.Kind() == reflect.Interface && !v.IsNil():
+		// The dynamic value of an interface is not settable: visit a copy
+		// and store it back.
+		cp := reflect.New(v.Elem().Type()).Elem()
+		cp.Set(v.Elem())
+		walkValues(cp, path, visit)
+		if v.CanSet() {
+			v.Set(cp)
+		}
+		return
+	case v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface:
+		return
+	}
+	if path != "" {
+		visit(path, v)
+	}
+	if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		// Scalar-like type such as time.Time: do not descend.
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+				continue
+			}
+			name, _, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" {
+				ft := f.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					// Embedded struct without a json name: its fields are inlined.
+					walkValues(v.Field(i), path, func(p string, ev reflect.Value) {
+						if p != path {
+							visit(p, ev)
+						}
+					})
+					continue
+				}
+			}
+			if name == "" {
+				name = f.Name
+			}
+			walkValues(v.Field(i), joinPath(path, name), visit)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkValues(v.Index(i), fmt.Sprintf("%s[%d]", path, i), visit)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			cp := reflect.New(iter.Value().Type()).Elem()
+			cp.Set(iter.Value())
+			walkValues(cp, joinPath(path, fmt.Sprint(iter.Key().Interface())), visit)
+			v.SetMapIndex(iter.Key(), cp)
+		}
+	}
+}
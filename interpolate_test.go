@@ -0,0 +1,101 @@
+package confstore
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type interpEndpoint struct {
+	URL string `json:"url"`
+}
+
+type interpConf struct {
+	BaseURL   string                    `json:"baseURL"`
+	Port      int                       `json:"port"`
+	Timeout   time.Duration             `json:"timeout"`
+	Users     string                    `json:"users"`
+	Addr      *string                   `json:"addr"`
+	Endpoints []interpEndpoint          `json:"endpoints"`
+	Tenants   map[string]interpEndpoint `json:"tenants"`
+	Literal   string                    `json:"literal"`
+}
+
+func TestInterpolate(t *testing.T) {
+	addr := "localhost:${ref:port}"
+	conf := interpConf{
+		BaseURL:   "https://api.example.com",
+		Port:      8080,
+		Timeout:   5 * time.Second,
+		Users:     "${ref:baseURL}/users?timeout=${ref:timeout}",
+		Addr:      &addr,
+		Endpoints: []interpEndpoint{{URL: "${ref:users}"}},
+		Tenants:   map[string]interpEndpoint{"acme": {URL: "${ref:endpoints[0].url}&tenant=acme"}},
+		Literal:   "$${ref:baseURL}",
+	}
+	if err := Interpolate(&conf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	users := "https://api.example.com/users?timeout=5s"
+	if conf.Users != users || conf.Endpoints[0].URL != users {
+		t.Fatalf("references not resolved: %+v", conf)
+	}
+	if conf.Tenants["acme"].URL != users+"&tenant=acme" {
+		t.Fatalf("map value not resolved: %+v", conf.Tenants)
+	}
+	if *conf.Addr != "localhost:8080" {
+		t.Fatalf("pointer field not resolved: %q", *conf.Addr)
+	}
+	if conf.Literal != "${ref:baseURL}" {
+		t.Fatalf("escape not honored: %q", conf.Literal)
+	}
+}
+
+func TestInterpolate_Errors(t *testing.T) {
+	conf := interpConf{
+		BaseURL: "${ref:users}",
+		Users:   "${ref:baseURL}/users",
+		Literal: "${ref:missing}",
+	}
+	err := Interpolate(&conf)
+	if !errors.Is(err, ErrRefCycle) || !errors.Is(err, ErrRefNotFound) {
+		t.Fatalf("expected cycle and not-found errors, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "baseURL -> users -> baseURL") {
+		t.Fatalf("cycle path missing from %q", err)
+	}
+	if conf.BaseURL != "${ref:users}" {
+		t.Fatalf("config modified despite errors: %+v", conf)
+	}
+
+	bad := interpConf{Users: "${ref:endpoints}"}
+	if err = Interpolate(&bad); err == nil {
+		t.Fatal("expected error referencing a non-scalar")
+	}
+}
+
+func TestInterpolate_InterfaceValues(t *testing.T) {
+	type conf struct {
+		Base  string         `json:"base"`
+		Extra any            `json:"extra"`
+		Meta  map[string]any `json:"meta"`
+	}
+	c := conf{
+		Base:  "https://api.example.com",
+		Extra: "${ref:base}/extra",
+		Meta:  map[string]any{"users": "${ref:base}/users", "nested": map[string]any{"url": "${ref:meta.users}?x=1"}},
+	}
+	if err := Interpolate(&c); err != nil {
+		t.Fatalf("Interpolate error: %v", err)
+	}
+	if c.Extra != "https://api.example.com/extra" {
+		t.Fatalf("any field not written back: %v", c.Extra)
+	}
+	if c.Meta["users"] != "https://api.example.com/users" {
+		t.Fatalf("map[string]any value not written back: %v", c.Meta["users"])
+	}
+	if nested := c.Meta["nested"].(map[string]any); nested["url"] != "https://api.example.com/users?x=1" {
+		t.Fatalf("nested map value not written back: %v", nested["url"])
+	}
+}
@@ -0,0 +1,210 @@
+// Package manifest renders configuration into Kubernetes ConfigMap and Secret
+// manifests, so GitOps pipelines can treat the Go config as the single source of truth.
+//
+// The configuration is encoded as JSON and stored under a single data key
+// (default "config.json"). Selected top-level sections can be exported on
+// their own and individual values can be redacted by dotted key path. ConfigMap
+// also redacts every field tagged secret:"true"; Secret keeps them, since that
+// is where they belong.
+package manifest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-sphere/confstore"
+)
+
+// ErrSectionNotFound indicates that a section selected with WithSections is missing from the config.
+var ErrSectionNotFound = errors.New("manifest: section not found")
+
+type options struct {
+	namespace string
+	dataKey   string
+	labels    map[string]string
+	sections  []string
+	redact    []string
+}
+
+// Option configures manifest rendering.
+type Option func(*options)
+
+// WithNamespace sets metadata.namespace. Default: omitted.
+func WithNamespace(ns string) Option { return func(o *options) { o.namespace = ns } }
+
+// WithDataKey sets the data key holding the encoded config. Default: "config.json".
+func WithDataKey(key string) Option { return func(o *options) { o.dataKey = key } }
+
+// WithLabel adds a metadata label.
+func WithLabel(key, value string) Option {
+	return func(o *options) {
+		if o.labels == nil {
+			o.labels = make(map[string]string)
+		}
+		o.labels[key] = value
+	}
+}
+
+// WithSections exports only the given top-level keys of the config.
+// A missing section results in ErrSectionNotFound.
+func WithSections(keys ...string) Option {
+	return func(o *options) { o.sections = append(o.sections, keys...) }
+}
+
+// WithRedact replaces the values at the given dotted key paths (e.g. "db.password")
+// with confstore.Redacted. Paths that do not exist are ignored.
+func WithRedact(paths ...string) Option {
+	return func(o *options) { o.redact = append(o.redact, paths...) }
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{dataKey: "config.json"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// ConfigMap renders config as a ConfigMap manifest in YAML. The encoded config
+// is stored as an indented JSON literal block so that diffs stay readable.
+// Fields tagged secret:"true" are redacted.
+func ConfigMap(name string, config any, opts ...Option) ([]byte, error) {
+	o := newOptions(opts...)
+	data, err := encode(config, o, true)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = writeHeader(&buf, "ConfigMap", name, o); err != nil {
+		return nil, err
+	}
+	buf.WriteString("data:\n")
+	fmt.Fprintf(&buf, "  %s: |-\n", quote(o.dataKey))
+	for _, line := range strings.Split(string(data), "\n") {
+		buf.WriteString("    ")
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// Secret renders config as an Opaque Secret manifest in YAML, with the encoded
+// config base64-encoded under the data key as Kubernetes requires.
+func Secret(name string, config any, opts ...Option) ([]byte, error) {
+	o := newOptions(opts...)
+	data, err := encode(config, o, false)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = writeHeader(&buf, "Secret", name, o); err != nil {
+		return nil, err
+	}
+	buf.WriteString("type: Opaque\n")
+	buf.WriteString("data:\n")
+	fmt.Fprintf(&buf, "  %s: %s\n", quote(o.dataKey), base64.StdEncoding.EncodeToString(data))
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, kind, name string, o *options) error {
+	if name == "" {
+		return errors.New("manifest: name is required")
+	}
+	if o.dataKey == "" {
+		return errors.New("manifest: data key is required")
+	}
+	buf.WriteString("apiVersion: v1\n")
+	fmt.Fprintf(buf, "kind: %s\n", kind)
+	buf.WriteString("metadata:\n")
+	fmt.Fprintf(buf, "  name: %s\n", quote(name))
+	if o.namespace != "" {
+		fmt.Fprintf(buf, "  namespace: %s\n", quote(o.namespace))
+	}
+	if len(o.labels) > 0 {
+		buf.WriteString("  labels:\n")
+		keys := make([]string, 0, len(o.labels))
+		for k := range o.labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(buf, "    %s: %s\n", quote(k), quote(o.labels[k]))
+		}
+	}
+	return nil
+}
+
+// encode converts config to indented JSON after applying section selection and
+// redaction; secrets selects whether fields tagged secret:"true" are redacted.
+func encode(config any, o *options, secrets bool) ([]byte, error) {
+	var raw []byte
+	var err error
+	if secrets {
+		raw, err = confstore.MarshalRedacted(config)
+	} else {
+		raw, err = json.Marshal(config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("manifest: encode config: %w", err)
+	}
+	if len(o.sections) == 0 && len(o.redact) == 0 {
+		var out bytes.Buffer
+		if err = json.Indent(&out, raw, "", "  "); err != nil {
+			return nil, err
+		}
+		return out.Bytes(), nil
+	}
+	var tree any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err = dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("manifest: decode config: %w", err)
+	}
+	if len(o.sections) > 0 {
+		root, ok := tree.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("manifest: sections require an object config")
+		}
+		selected := make(map[string]any, len(o.sections))
+		for _, key := range o.sections {
+			v, ok := root[key]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrSectionNotFound, key)
+			}
+			selected[key] = v
+		}
+		tree = selected
+	}
+	for _, path := range o.redact {
+		redact(tree, strings.Split(path, "."))
+	}
+	return json.MarshalIndent(tree, "", "  ")
+}
+
+func redact(node any, path []string) {
+	obj, ok := node.(map[string]any)
+	if !ok {
+		return
+	}
+	v, ok := obj[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		obj[path[0]] = confstore.Redacted
+		return
+	}
+	redact(v, path[1:])
+}
+
+// quote renders s as a YAML double-quoted scalar. JSON string escapes are a
+// subset of YAML's, so json.Marshal produces a valid YAML string.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,97 @@
+package manifest
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type dbConf struct {
+	DSN      string `json:"dsn"`
+	Password string `json:"password"`
+}
+
+type appConf struct {
+	Addr string `json:"addr"`
+	DB   dbConf `json:"db"`
+}
+
+func TestConfigMap(t *testing.T) {
+	conf := appConf{Addr: ":8080", DB: dbConf{DSN: "postgres://db", Password: "hunter2"}}
+	got, err := ConfigMap("app", conf, WithNamespace("prod"), WithLabel("app", "demo"), WithRedact("db.password"))
+	if err != nil {
+		t.Fatalf("ConfigMap error: %v", err)
+	}
+	want := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "app"
+  namespace: "prod"
+  labels:
+    "app": "demo"
+data:
+  "config.json": |-
+    {
+      "addr": ":8080",
+      "db": {
+        "dsn": "postgres://db",
+        "password": "REDACTED"
+      }
+    }
+`
+	if string(got) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSecretSections(t *testing.T) {
+	conf := appConf{Addr: ":8080", DB: dbConf{DSN: "postgres://db", Password: "hunter2"}}
+	got, err := Secret("app-db", conf, WithSections("db"), WithDataKey("db.json"))
+	if err != nil {
+		t.Fatalf("Secret error: %v", err)
+	}
+	out := string(got)
+	if !strings.Contains(out, "kind: Secret\n") || !strings.Contains(out, "type: Opaque\n") {
+		t.Fatalf("unexpected manifest:\n%s", out)
+	}
+	_, encoded, _ := strings.Cut(out, `"db.json": `)
+	payload, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if strings.Contains(string(payload), "addr") || !strings.Contains(string(payload), "hunter2") {
+		t.Fatalf("unexpected payload: %s", payload)
+	}
+}
+
+func TestMissingSection(t *testing.T) {
+	_, err := ConfigMap("app", appConf{}, WithSections("cache"))
+	if !errors.Is(err, ErrSectionNotFound) {
+		t.Fatalf("expected ErrSectionNotFound, got %v", err)
+	}
+}
+
+func TestConfigMapRedactsSecretFields(t *testing.T) {
+	type conf struct {
+		Addr  string `json:"addr"`
+		Token string `json:"token" secret:"true"`
+	}
+	c := conf{Addr: ":8080", Token: "hunter2"}
+	got, err := ConfigMap("app", c)
+	if err != nil {
+		t.Fatalf("ConfigMap error: %v", err)
+	}
+	if strings.Contains(string(got), "hunter2") || !strings.Contains(string(got), `"token": "REDACTED"`) {
+		t.Fatalf("secret field not redacted:\n%s", got)
+	}
+	got, err = Secret("app", c)
+	if err != nil {
+		t.Fatalf("Secret error: %v", err)
+	}
+	_, encoded, _ := strings.Cut(string(got), `"config.json": `)
+	payload, _ := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if !strings.Contains(string(payload), "hunter2") {
+		t.Fatalf("Secret should keep secret fields: %s", payload)
+	}
+}
@@ -0,0 +1,217 @@
+// Package migrate upgrades configuration documents written for older schema
+// versions before they are decoded into the latest config struct.
+//
+// Documents carry their schema version in a top-level key ("version" by
+// default). Migrations are registered per version step and operate on the
+// generic map form of the document:
+//
+//	m := migrate.New()
+//	m.Register(1, 2, func(doc map[string]any) error {
+//		doc["listen"] = doc["addr"]
+//		delete(doc, "addr")
+//		return nil
+//	})
+//	cfg, err := confstore.Load[AppConf](m.Provider(p, codec.JsonCodec()), codec.JsonCodec())
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/go-sphere/confstore/codec"
+	"github.com/go-sphere/confstore/provider"
+)
+
+var (
+	// ErrMissingVersion indicates that a document has no version key and no default version is configured.
+	ErrMissingVersion = errors.New("migrate: document has no version")
+	// ErrInvalidVersion indicates that the version key does not hold an integer.
+	ErrInvalidVersion = errors.New("migrate: invalid document version")
+	// ErrNoPath indicates that no registered migration starts at the document's current version.
+	ErrNoPath = errors.New("migrate: no migration path")
+	// ErrUnknownVersion indicates that a document is newer than the latest known version.
+	ErrUnknownVersion = errors.New("migrate: document version is newer than latest")
+)
+
+// Func migrates a document in place from one version to the next.
+// It does not need to update the version key; the Migrator does that.
+type Func func(doc map[string]any) error
+
+type step struct {
+	to int
+	fn Func
+}
+
+type options struct {
+	key            string
+	defaultVersion int
+	hasDefault     bool
+}
+
+// Option configures a Migrator.
+type Option func(*options)
+
+// WithVersionKey sets the top-level key holding the document version. Default: "version".
+func WithVersionKey(key string) Option { return func(o *options) { o.key = key } }
+
+// WithDefaultVersion treats documents without a version key as being at version v,
+// which is useful for files written before versioning was introduced.
+func WithDefaultVersion(v int) Option {
+	return func(o *options) {
+		o.defaultVersion = v
+		o.hasDefault = true
+	}
+}
+
+// Migrator holds registered migrations and applies them in order.
+// Register all migrations before using the Migrator concurrently.
+type Migrator struct {
+	opts   *options
+	steps  map[int]step
+	latest int
+}
+
+// New creates an empty Migrator.
+func New(opts ...Option) *Migrator {
+	o := &options{key: "version"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Migrator{opts: o, steps: make(map[int]step)}
+}
+
+// Register adds a migration from version from to version to. Like other
+// registration functions in this module it panics on programmer errors:
+// a nil fn, to not greater than from, or a second migration starting at from.
+func (m *Migrator) Register(from, to int, fn Func) {
+	if fn == nil {
+		panic("migrate: Register fn is nil")
+	}
+	if to <= from {
+		panic(fmt.Sprintf("migrate: Register %d -> %d does not move forward", from, to))
+	}
+	if _, dup := m.steps[from]; dup {
+		panic(fmt.Sprintf("migrate: Register called twice for version %d", from))
+	}
+	m.steps[from] = step{to: to, fn: fn}
+	if to > m.latest {
+		m.latest = to
+	}
+}
+
+// Latest returns the highest version reachable by the registered migrations.
+func (m *Migrator) Latest() int {
+	return m.latest
+}
+
+// Migrate applies pending migrations to doc until it reaches Latest and
+// updates the version key after each step. It returns the version the
+// document started at.
+func (m *Migrator) Migrate(doc map[string]any) (int, error) {
+	from, err := m.version(doc)
+	if err != nil {
+		return 0, err
+	}
+	if from > m.latest {
+		return from, fmt.Errorf("%w: %d > %d", ErrUnknownVersion, from, m.latest)
+	}
+	for v := from; v < m.latest; {
+		s, ok := m.steps[v]
+		if !ok {
+			return from, fmt.Errorf("%w: from version %d", ErrNoPath, v)
+		}
+		if err = s.fn(doc); err != nil {
+			return from, fmt.Errorf("migrate %d -> %d: %w", v, s.to, err)
+		}
+		v = s.to
+		doc[m.opts.key] = v
+	}
+	return from, nil
+}
+
+func (m *Migrator) version(doc map[string]any) (int, error) {
+	raw, ok := doc[m.opts.key]
+	if !ok {
+		if m.opts.hasDefault {
+			return m.opts.defaultVersion, nil
+		}
+		return 0, ErrMissingVersion
+	}
+	switch v := raw.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v), nil
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n), nil
+		}
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %v", ErrInvalidVersion, raw)
+}
+
+// Provider wraps p so that every Read returns the document migrated to the
+// latest version. The codec is used to decode the raw document into its
+// generic map form and to encode it back after migration.
+//
+// Documents that are valid JSON bypass the codec: they are decoded with
+// json.Number for numbers, so migrations see json.Number rather than
+// float64 and integers beyond 2^53 survive unchanged, and are re-encoded as
+// JSON.
+func (m *Migrator) Provider(p provider.Provider, c codec.Codec) provider.Provider {
+	return &migrating{migrator: m, provider: p, codec: c}
+}
+
+type migrating struct {
+	migrator *Migrator
+	provider provider.Provider
+	codec    codec.Codec
+}
+
+func (mp *migrating) Read(ctx context.Context) ([]byte, error) {
+	data, err := mp.provider.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	isJSON := json.Valid(data)
+	var doc map[string]any
+	if isJSON {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&doc)
+	} else {
+		err = mp.codec.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("migrate: decode document: %w", err)
+	}
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	from, err := mp.migrator.Migrate(doc)
+	if err != nil {
+		return nil, err
+	}
+	if from == mp.migrator.latest {
+		return data, nil
+	}
+	if isJSON {
+		return json.Marshal(doc)
+	}
+	return mp.codec.Marshal(doc)
+}
@@ -0,0 +1,100 @@
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-sphere/confstore/codec"
+	"github.com/go-sphere/confstore/provider"
+)
+
+func newTestMigrator(opts ...Option) *Migrator {
+	m := New(opts...)
+	m.Register(1, 2, func(doc map[string]any) error {
+		doc["listen"] = doc["addr"]
+		delete(doc, "addr")
+		return nil
+	})
+	m.Register(2, 3, func(doc map[string]any) error {
+		doc["mode"] = "prod"
+		return nil
+	})
+	return m
+}
+
+func staticProvider(s string) provider.Provider {
+	return provider.ReaderFunc(func(ctx context.Context) ([]byte, error) { return []byte(s), nil })
+}
+
+func TestProviderMigratesToLatest(t *testing.T) {
+	m := newTestMigrator()
+	data, err := m.Provider(staticProvider(`{"version":1,"addr":":80"}`), codec.JsonCodec()).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	var got map[string]any
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got["version"] != float64(3) || got["listen"] != ":80" || got["mode"] != "prod" || got["addr"] != nil {
+		t.Fatalf("unexpected migrated document: %v", got)
+	}
+}
+
+func TestProviderKeepsLargeIntegers(t *testing.T) {
+	raw := `{"version":1,"addr":":80","id":9007199254740993}`
+	data, err := newTestMigrator().Provider(staticProvider(raw), codec.JsonCodec()).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	var got struct {
+		ID int64 `json:"id"`
+	}
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.ID != 9007199254740993 {
+		t.Fatalf("large integer lost precision: %s", data)
+	}
+}
+
+func TestProviderLatestUnchanged(t *testing.T) {
+	raw := `{"version": 3, "listen": ":80"}`
+	data, err := newTestMigrator().Provider(staticProvider(raw), codec.JsonCodec()).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if string(data) != raw {
+		t.Fatalf("got %s, want original bytes", data)
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	m := newTestMigrator()
+	if _, err := m.Migrate(map[string]any{}); !errors.Is(err, ErrMissingVersion) {
+		t.Fatalf("expected ErrMissingVersion, got %v", err)
+	}
+	if _, err := m.Migrate(map[string]any{"version": 9}); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("expected ErrUnknownVersion, got %v", err)
+	}
+	if _, err := m.Migrate(map[string]any{"version": 0}); !errors.Is(err, ErrNoPath) {
+		t.Fatalf("expected ErrNoPath, got %v", err)
+	}
+	if _, err := m.Migrate(map[string]any{"version": "x"}); !errors.Is(err, ErrInvalidVersion) {
+		t.Fatalf("expected ErrInvalidVersion, got %v", err)
+	}
+}
+
+func TestDefaultVersion(t *testing.T) {
+	m := newTestMigrator(WithDefaultVersion(1), WithVersionKey("schema"))
+	doc := map[string]any{"addr": ":80"}
+	from, err := m.Migrate(doc)
+	if err != nil {
+		t.Fatalf("Migrate error: %v", err)
+	}
+	if from != 1 || doc["schema"] != 3 {
+		t.Fatalf("unexpected result: from=%d doc=%v", from, doc)
+	}
+}
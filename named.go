@@ -0,0 +1,141 @@
+package confstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrDuplicateName indicates that two entries of a named list share the same name.
+	ErrDuplicateName = errors.New("confstore: duplicate name in named list")
+	// ErrMissingName indicates that an entry of a named list has no usable name.
+	ErrMissingName = errors.New("confstore: missing name in named list entry")
+)
+
+// NamedList decodes a list of named objects, e.g. [{"name":"a",...},{"name":"b",...}],
+// into a lookup by name while keeping the order the entries appeared in.
+// Entries must carry a non-empty "name" key, and names must be unique.
+// The name is also decoded into T if T has a matching field.
+//
+// NamedList implements json.Unmarshaler and json.Marshaler; for other codecs,
+// decode into a slice and use ByName.
+type NamedList[T any] struct {
+	items map[string]T
+	names []string
+}
+
+// Get returns the entry with the given name.
+func (l NamedList[T]) Get(name string) (T, bool) {
+	v, ok := l.items[name]
+	return v, ok
+}
+
+// Names returns the entry names in document order.
+func (l NamedList[T]) Names() []string {
+	return append([]string(nil), l.names...)
+}
+
+// Map returns a copy of the entries keyed by name.
+func (l NamedList[T]) Map() map[string]T {
+	m := make(map[string]T, len(l.items))
+	for k, v := range l.items {
+		m[k] = v
+	}
+	return m
+}
+
+// Len returns the number of entries.
+func (l NamedList[T]) Len() int {
+	return len(l.names)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *NamedList[T]) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	items := make(map[string]T, len(raw))
+	names := make([]string, 0, len(raw))
+	seen := make(map[string]int, len(raw))
+	for i, entry := range raw {
+		var head struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(entry, &head); err != nil {
+			return fmt.Errorf("named list entry[%d]: %w", i, err)
+		}
+		if head.Name == "" {
+			return fmt.Errorf("%w: entry[%d]", ErrMissingName, i)
+		}
+		if first, dup := seen[head.Name]; dup {
+			return fmt.Errorf("%w: %q at entry[%d] and entry[%d]", ErrDuplicateName, head.Name, first, i)
+		}
+		var v T
+		if err := json.Unmarshal(entry, &v); err != nil {
+			return fmt.Errorf("named list entry %q: %w", head.Name, err)
+		}
+		seen[head.Name] = i
+		items[head.Name] = v
+		names = append(names, head.Name)
+	}
+	l.items, l.names = items, names
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. Entries are written in document order;
+// a "name" key is added to entries whose encoding does not already contain one.
+func (l NamedList[T]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, name := range l.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		data, err := json.Marshal(l.items[name])
+		if err != nil {
+			return nil, fmt.Errorf("named list entry %q: %w", name, err)
+		}
+		var obj map[string]json.RawMessage
+		if err = json.Unmarshal(data, &obj); err != nil {
+			return nil, fmt.Errorf("named list entry %q: not an object: %w", name, err)
+		}
+		if _, ok := obj["name"]; !ok {
+			if obj == nil {
+				obj = make(map[string]json.RawMessage, 1)
+			}
+			obj["name"], _ = json.Marshal(name)
+			if data, err = json.Marshal(obj); err != nil {
+				return nil, err
+			}
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// ByName indexes already-decoded entries by the name returned from nameOf,
+// applying the same rules as NamedList: names must be non-empty and unique.
+// It returns the entries keyed by name and the names in slice order.
+// It is useful with codecs that cannot decode into NamedList directly.
+func ByName[T any](entries []T, nameOf func(T) string) (map[string]T, []string, error) {
+	items := make(map[string]T, len(entries))
+	names := make([]string, 0, len(entries))
+	seen := make(map[string]int, len(entries))
+	for i, v := range entries {
+		name := nameOf(v)
+		if name == "" {
+			return nil, nil, fmt.Errorf("%w: entry[%d]", ErrMissingName, i)
+		}
+		if first, dup := seen[name]; dup {
+			return nil, nil, fmt.Errorf("%w: %q at entry[%d] and entry[%d]", ErrDuplicateName, name, first, i)
+		}
+		seen[name] = i
+		items[name] = v
+		names = append(names, name)
+	}
+	return items, names, nil
+}
@@ -0,0 +1,70 @@
+package confstore
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type upstream struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+func TestNamedList_Unmarshal(t *testing.T) {
+	var conf struct {
+		Upstreams NamedList[upstream] `json:"upstreams"`
+	}
+	data := []byte(`{"upstreams":[{"name":"b","url":"http://b"},{"name":"a","url":"http://a"}]}`)
+	if err := json.Unmarshal(data, &conf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := conf.Upstreams.Names(); !reflect.DeepEqual(got, []string{"b", "a"}) {
+		t.Fatalf("got order %v, want [b a]", got)
+	}
+	a, ok := conf.Upstreams.Get("a")
+	if !ok || a.URL != "http://a" || a.Name != "a" {
+		t.Fatalf("unexpected entry a: %+v (ok=%v)", a, ok)
+	}
+
+	out, err := json.Marshal(conf.Upstreams)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	want := `[{"name":"b","url":"http://b"},{"name":"a","url":"http://a"}]`
+	if string(out) != want {
+		t.Fatalf("got %s, want %s", out, want)
+	}
+}
+
+func TestNamedList_Duplicate(t *testing.T) {
+	var l NamedList[upstream]
+	err := json.Unmarshal([]byte(`[{"name":"a"},{"name":"a"}]`), &l)
+	if !errors.Is(err, ErrDuplicateName) {
+		t.Fatalf("expected ErrDuplicateName, got %v", err)
+	}
+}
+
+func TestNamedList_MissingName(t *testing.T) {
+	var l NamedList[upstream]
+	err := json.Unmarshal([]byte(`[{"url":"x"}]`), &l)
+	if !errors.Is(err, ErrMissingName) {
+		t.Fatalf("expected ErrMissingName, got %v", err)
+	}
+}
+
+func TestByName(t *testing.T) {
+	entries := []upstream{{Name: "x"}, {Name: "y"}}
+	m, names, err := ByName(entries, func(u upstream) string { return u.Name })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m) != 2 || !reflect.DeepEqual(names, []string{"x", "y"}) {
+		t.Fatalf("unexpected result: %v %v", m, names)
+	}
+	_, _, err = ByName(append(entries, upstream{Name: "x"}), func(u upstream) string { return u.Name })
+	if !errors.Is(err, ErrDuplicateName) {
+		t.Fatalf("expected ErrDuplicateName, got %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+// Package apollo provides a configuration provider for the Apollo config center.
+//
+// A config is addressed by app ID, cluster and namespace. Read fetches the
+// latest release through the config service API and Watch subscribes to
+// releases with the notifications/v2 long-polling API, surfacing them through
+// provider.Watcher.
+//
+// Namespaces of format json, yaml, xml or txt (e.g. "app.json") are returned
+// verbatim. Properties namespaces (no extension or ".properties") are returned
+// as a flat JSON object of string keys and values.
+package apollo
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sphere/confstore/internal/wait"
+	"github.com/go-sphere/confstore/provider"
+)
+
+var (
+	// ErrNotFound indicates that the requested namespace does not exist or has no release.
+	ErrNotFound = provider.NewNotFound("apollo provider: namespace not found")
+	// ErrBodyTooLarge indicates a response body exceeding the configured max size.
+	ErrBodyTooLarge = errors.New("apollo provider: body too large")
+)
+
+// DefaultMaxBodySize is the response size limit used unless WithMaxBodySize is given.
+const DefaultMaxBodySize = 10 << 20
+
+// Apollo reads a single namespace from an Apollo config service.
+type Apollo struct {
+	server string
+	appID  string
+	opts   *options
+}
+
+type options struct {
+	cluster        string
+	namespace      string
+	secret         string
+	client         *http.Client
+	reconnectDelay time.Duration
+	maxBodySize    int64
+}
+
+// Option configures optional behavior for the Apollo provider.
+type Option func(*options)
+
+// WithCluster sets the cluster name. Default: "default".
+func WithCluster(cluster string) Option { return func(o *options) { o.cluster = cluster } }
+
+// WithNamespace sets the namespace name. Default: "application".
+func WithNamespace(ns string) Option { return func(o *options) { o.namespace = ns } }
+
+// WithSecret enables Apollo access-key authentication; every request is
+// signed with the given secret.
+func WithSecret(secret string) Option { return func(o *options) { o.secret = secret } }
+
+// WithClient sets a custom HTTP client. Its timeout must exceed the server's
+// long-poll hold time (60s by default) for Watch to work.
+func WithClient(c *http.Client) Option { return func(o *options) { o.client = c } }
+
+// WithReconnectDelay sets the pause before retrying after a failed request in Watch. Default: 3s.
+func WithReconnectDelay(d time.Duration) Option { return func(o *options) { o.reconnectDelay = d } }
+
+// WithMaxBodySize limits response bodies to n bytes; larger responses fail
+// with ErrBodyTooLarge. A non-positive value disables the limit. Default:
+// DefaultMaxBodySize.
+func WithMaxBodySize(n int64) Option { return func(o *options) { o.maxBodySize = n } }
+
+// New creates an Apollo provider. server is the base URL of the config
+// service, e.g. "http://127.0.0.1:8080".
+func New(server, appID string, opts ...Option) *Apollo {
+	o := &options{cluster: "default", namespace: "application", reconnectDelay: 3 * time.Second, maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.client == nil {
+		o.client = &http.Client{}
+	}
+	return &Apollo{server: strings.TrimRight(server, "/"), appID: appID, opts: o}
+}
+
+// Read implements provider.Provider.
+func (a *Apollo) Read(ctx context.Context) ([]byte, error) {
+	p := "/configs/" + url.PathEscape(a.appID) + "/" + url.PathEscape(a.opts.cluster) + "/" + url.PathEscape(a.opts.namespace)
+	body, status, err := a.get(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case status == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s/%s/%s", ErrNotFound, a.appID, a.opts.cluster, a.opts.namespace)
+	case status < 200 || status >= 300:
+		return nil, fmt.Errorf("apollo provider: get config %s unexpected status %d", a.opts.namespace, status)
+	}
+	var resp struct {
+		Configurations map[string]string `json:"configurations"`
+	}
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("apollo provider: decode config %s: %w", a.opts.namespace, err)
+	}
+	if !isProperties(a.opts.namespace) {
+		return []byte(resp.Configurations["content"]), nil
+	}
+	if resp.Configurations == nil {
+		resp.Configurations = map[string]string{}
+	}
+	data, err := json.Marshal(resp.Configurations)
+	if err != nil {
+		return nil, fmt.Errorf("apollo provider: encode config %s: %w", a.opts.namespace, err)
+	}
+	return data, nil
+}
+
+// errNoNotification reports a notifications response without a newer ID for
+// the watched namespace. Watch retries it after the reconnect delay, like a
+// failed request, rather than re-polling immediately.
+var errNoNotification = errors.New("apollo provider: no notification for namespace")
+
+type notification struct {
+	NamespaceName  string `json:"namespaceName"`
+	NotificationID int64  `json:"notificationId"`
+}
+
+// Watch implements provider.Watcher using the notifications/v2 long-polling
+// API. The current release is delivered first; afterwards onChange is called
+// for each new release whose content differs from the previous one. Failed
+// requests and responses without news for the namespace are retried after the
+// reconnect delay.
+func (a *Apollo) Watch(ctx context.Context, onChange func(data []byte)) error {
+	var last []byte
+	delivered := false
+	id := int64(-1)
+	for {
+		next, err := a.notifications(ctx, id)
+		if err == nil && next != id {
+			var data []byte
+			if data, err = a.Read(ctx); err == nil {
+				id = next
+				if !delivered || !bytes.Equal(data, last) {
+					last, delivered = data, true
+					onChange(data)
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			if err = wait.Sleep(ctx, a.opts.reconnectDelay); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// notifications blocks until the server reports a notification ID newer than
+// id, returning it, or until the long-poll times out, returning id unchanged.
+// A response without a newer ID for the namespace yields errNoNotification.
+func (a *Apollo) notifications(ctx context.Context, id int64) (int64, error) {
+	ns := notificationName(a.opts.namespace)
+	param, err := json.Marshal([]notification{{NamespaceName: ns, NotificationID: id}})
+	if err != nil {
+		return id, fmt.Errorf("apollo provider: encode notifications: %w", err)
+	}
+	q := url.Values{"appId": {a.appID}, "cluster": {a.opts.cluster}, "notifications": {string(param)}}
+	body, status, err := a.get(ctx, "/notifications/v2?"+q.Encode())
+	if err != nil {
+		return id, err
+	}
+	switch {
+	case status == http.StatusNotModified:
+		return id, nil
+	case status < 200 || status >= 300:
+		return id, fmt.Errorf("apollo provider: notifications unexpected status %d", status)
+	}
+	var resp []notification
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return id, fmt.Errorf("apollo provider: decode notifications: %w", err)
+	}
+	next := id
+	for _, n := range resp {
+		if n.NamespaceName == ns && n.NotificationID > next {
+			next = n.NotificationID
+		}
+	}
+	if next == id {
+		return id, errNoNotification
+	}
+	return next, nil
+}
+
+// get issues a signed GET request for pathAndQuery relative to the server.
+func (a *Apollo) get(ctx context.Context, pathAndQuery string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.server+pathAndQuery, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("apollo provider: build request: %w", err)
+	}
+	if a.opts.secret != "" {
+		ts := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		req.Header.Set("Authorization", "Apollo "+a.appID+":"+signature(ts, req.URL.RequestURI(), a.opts.secret))
+		req.Header.Set("Timestamp", ts)
+	}
+	resp, err := a.opts.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("apollo provider: do request %s: %w", req.URL.Path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var body io.Reader = resp.Body
+	if a.opts.maxBodySize > 0 {
+		body = io.LimitReader(resp.Body, a.opts.maxBodySize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("apollo provider: read body %s: %w", req.URL.Path, err)
+	}
+	if a.opts.maxBodySize > 0 && int64(len(data)) > a.opts.maxBodySize {
+		return nil, 0, fmt.Errorf("%w: %s exceeds limit %d", ErrBodyTooLarge, req.URL.Path, a.opts.maxBodySize)
+	}
+	return data, resp.StatusCode, nil
+}
+
+// signature computes the Apollo access-key signature:
+// base64(HMAC-SHA1(secret, timestamp + "\n" + pathWithQuery)).
+func signature(timestamp, pathWithQuery, secret string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + pathWithQuery))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// isProperties reports whether namespace is a properties namespace. Public
+// namespaces may contain dots (e.g. "FX.apollo"), so only the file formats
+// Apollo knows about count as extensions.
+func isProperties(namespace string) bool {
+	switch path.Ext(namespace) {
+	case ".json", ".yaml", ".yml", ".xml", ".txt":
+		return false
+	}
+	return true
+}
+
+// notificationName returns the namespace name as used by the notifications
+// API, which omits the ".properties" suffix.
+func notificationName(namespace string) string {
+	return strings.TrimSuffix(namespace, ".properties")
+}
@@ -0,0 +1,231 @@
+package apollo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/confstore/provider"
+	"github.com/go-sphere/confstore/provider/providertest"
+)
+
+type fakeServer struct {
+	mu        sync.Mutex
+	secret    string
+	releases  map[string]map[string]string // namespace -> configurations
+	notifyIDs map[string]int64
+}
+
+func (f *fakeServer) set(ns string, conf map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.releases[ns] = conf
+	f.notifyIDs[notificationName(ns)]++
+}
+
+func (f *fakeServer) handler(t *testing.T) http.Handler {
+	authed := func(w http.ResponseWriter, r *http.Request) bool {
+		if f.secret == "" {
+			return true
+		}
+		want := "Apollo app:" + signature(r.Header.Get("Timestamp"), r.URL.RequestURI(), f.secret)
+		if r.Header.Get("Authorization") != want {
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /configs/{app}/{cluster}/{ns}", func(w http.ResponseWriter, r *http.Request) {
+		if !authed(w, r) {
+			return
+		}
+		f.mu.Lock()
+		conf, ok := f.releases[r.PathValue("ns")]
+		f.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"configurations": conf, "releaseKey": "rk"})
+	})
+	mux.HandleFunc("GET /notifications/v2", func(w http.ResponseWriter, r *http.Request) {
+		if !authed(w, r) {
+			return
+		}
+		var req []notification
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("notifications")), &req); err != nil || len(req) != 1 {
+			t.Errorf("malformed notifications param: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		deadline := time.After(50 * time.Millisecond)
+		for {
+			f.mu.Lock()
+			id := f.notifyIDs[req[0].NamespaceName]
+			f.mu.Unlock()
+			if id > req[0].NotificationID {
+				_ = json.NewEncoder(w).Encode([]notification{{NamespaceName: req[0].NamespaceName, NotificationID: id}})
+				return
+			}
+			select {
+			case <-deadline:
+				w.WriteHeader(http.StatusNotModified)
+				return
+			case <-r.Context().Done():
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	})
+	return mux
+}
+
+func newFakeServer(secret string) *fakeServer {
+	return &fakeServer{secret: secret, releases: map[string]map[string]string{}, notifyIDs: map[string]int64{}}
+}
+
+func TestRead(t *testing.T) {
+	f := newFakeServer("")
+	f.set("application", map[string]string{"port": "8080"})
+	f.set("app.json", map[string]string{"content": `{"a":1}`})
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	got, err := New(srv.URL, "app").Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if string(got) != `{"port":"8080"}` {
+		t.Fatalf("unexpected properties content: %s", got)
+	}
+
+	got, err = New(srv.URL, "app", WithNamespace("app.json")).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if string(got) != `{"a":1}` {
+		t.Fatalf("unexpected json content: %s", got)
+	}
+
+	_, err = New(srv.URL, "app", WithNamespace("missing")).Read(context.Background())
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestReadMaxBodySize(t *testing.T) {
+	f := newFakeServer("")
+	f.set("application", map[string]string{"port": "8080"})
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	_, err := New(srv.URL, "app", WithMaxBodySize(8)).Read(context.Background())
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+	if _, err := New(srv.URL, "app", WithMaxBodySize(0)).Read(context.Background()); err != nil {
+		t.Fatalf("Read without limit error: %v", err)
+	}
+}
+
+func TestReadWithSecret(t *testing.T) {
+	f := newFakeServer("s3cret")
+	f.set("application", map[string]string{})
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	if _, err := New(srv.URL, "app", WithSecret("s3cret")).Read(context.Background()); err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if _, err := New(srv.URL, "app", WithSecret("wrong")).Read(context.Background()); err == nil {
+		t.Fatalf("expected unauthorized error")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	f := newFakeServer("")
+	f.set("app.json", map[string]string{"content": "v1"})
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := make(chan string, 4)
+	done := make(chan error, 1)
+	go func() {
+		a := New(srv.URL, "app", WithNamespace("app.json"), WithReconnectDelay(10*time.Millisecond))
+		done <- a.Watch(ctx, func(data []byte) { got <- string(data) })
+	}()
+
+	if v := <-got; v != "v1" {
+		t.Fatalf("expected initial v1, got %q", v)
+	}
+	for i := 2; i <= 3; i++ {
+		want := "v" + strconv.Itoa(i)
+		f.set("app.json", map[string]string{"content": want})
+		select {
+		case v := <-got:
+			if v != want {
+				t.Fatalf("expected %s, got %q", want, v)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWatchBacksOffOnUnrelatedNotification(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls++
+		mu.Unlock()
+		_, _ = w.Write([]byte(`[{"namespaceName":"other","notificationId":7}]`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	err := New(srv.URL, "app", WithReconnectDelay(50*time.Millisecond)).Watch(ctx, func([]byte) {})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if polls > 6 {
+		t.Fatalf("expected polls to back off, got %d in 200ms", polls)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	serve := func(t *testing.T, f *fakeServer) string {
+		srv := httptest.NewServer(f.handler(t))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+	providertest.TestProvider(t, providertest.Harness{
+		New: func(t *testing.T, data []byte) provider.Provider {
+			f := newFakeServer("")
+			f.set("app.json", map[string]string{"content": string(data)})
+			return New(serve(t, f), "app", WithNamespace("app.json"))
+		},
+		Missing: func(t *testing.T) provider.Provider {
+			return New(serve(t, newFakeServer("")), "app", WithNamespace("app.json"))
+		},
+		HonorsCancellation: true,
+		// Release content travels in a JSON string.
+		TextOnly: true,
+	})
+}
@@ -0,0 +1,278 @@
+// Package awsparams provides configuration providers for AWS Systems Manager
+// Parameter Store and AWS Secrets Manager.
+//
+// Requests are signed with Signature Version 4 using only the standard
+// library. Credentials come from the environment by default; WithCredentials
+// plugs in any other source and WithAssumeRole exchanges them for a role's
+// temporary credentials.
+//
+// Unlike the AWS SDKs there is no default credential chain: shared config and
+// credentials files, EC2 instance metadata (IMDS), ECS container credentials
+// and web identity tokens are not read. On such hosts, pass a CredentialsFunc
+// backed by the source of your choice to WithCredentials.
+package awsparams
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-sphere/confstore/provider"
+)
+
+var (
+	// ErrNotFound indicates that the parameter, path or secret does not exist.
+	ErrNotFound = provider.NewNotFound("awsparams provider: not found")
+	// ErrNoCredentials indicates that no AWS credentials are available.
+	ErrNoCredentials = errors.New("awsparams provider: no credentials")
+)
+
+// Provider reads a parameter, a parameter path or a secret.
+type Provider struct {
+	opts *options
+	read func(ctx context.Context, creds Credentials) ([]byte, error)
+}
+
+type options struct {
+	region      string
+	client      *http.Client
+	credentials CredentialsFunc
+	decrypt     bool
+	endpoint    func(service, region string) string
+	roleARN     string
+	roleSession string
+}
+
+// Option configures optional behavior for the AWS providers.
+type Option func(*options)
+
+// WithRegion sets the AWS region. Default: AWS_REGION or AWS_DEFAULT_REGION.
+func WithRegion(region string) Option { return func(o *options) { o.region = region } }
+
+// WithClient sets a custom HTTP client.
+func WithClient(c *http.Client) Option { return func(o *options) { o.client = c } }
+
+// WithCredentials sets the credential source. Default: EnvCredentials.
+func WithCredentials(f CredentialsFunc) Option { return func(o *options) { o.credentials = f } }
+
+// WithDecryption controls whether SecureString parameters are decrypted. Default: true.
+func WithDecryption(decrypt bool) Option { return func(o *options) { o.decrypt = decrypt } }
+
+// WithAssumeRole assumes the given IAM role via STS before reading, using the
+// configured credentials as the source identity.
+func WithAssumeRole(roleARN, sessionName string) Option {
+	return func(o *options) { o.roleARN, o.roleSession = roleARN, sessionName }
+}
+
+// WithEndpoint overrides the service endpoint, e.g. for VPC endpoints or
+// local emulators. f receives the service name ("ssm", "secretsmanager" or
+// "sts") and the region. Default: https://<service>.<region>.amazonaws.com.
+func WithEndpoint(f func(service, region string) string) Option {
+	return func(o *options) { o.endpoint = f }
+}
+
+func newProvider(opts []Option) *Provider {
+	o := &options{region: defaultRegion(), credentials: EnvCredentials, decrypt: true, roleSession: "confstore"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.client == nil {
+		o.client = &http.Client{}
+	}
+	if o.endpoint == nil {
+		o.endpoint = func(service, region string) string { return "https://" + service + "." + region + ".amazonaws.com" }
+	}
+	if o.roleARN != "" {
+		o.credentials = assumeRole(o, o.credentials)
+	}
+	return &Provider{opts: o}
+}
+
+// NewParameter creates a provider that reads the value of a single SSM parameter.
+func NewParameter(name string, opts ...Option) *Provider {
+	p := newProvider(opts)
+	p.read = func(ctx context.Context, creds Credentials) ([]byte, error) {
+		var resp struct {
+			Parameter struct {
+				Value string `json:"Value"`
+			} `json:"Parameter"`
+		}
+		req := map[string]any{"Name": name, "WithDecryption": p.opts.decrypt}
+		if err := p.opts.call(ctx, creds, "ssm", "AmazonSSM.GetParameter", req, &resp); err != nil {
+			return nil, fmt.Errorf("awsparams provider: get parameter %s: %w", name, err)
+		}
+		return []byte(resp.Parameter.Value), nil
+	}
+	return p
+}
+
+// NewParameterPath creates a provider that reads every parameter below path
+// (recursively) and assembles them into a JSON object, nesting on "/". For
+// example, with path "/app/prod" the parameter "/app/prod/db/host" becomes
+// {"db":{"host":"..."}}. All values are strings.
+func NewParameterPath(path string, opts ...Option) *Provider {
+	p := newProvider(opts)
+	prefix := "/" + strings.Trim(path, "/")
+	p.read = func(ctx context.Context, creds Credentials) ([]byte, error) {
+		doc := map[string]any{}
+		token := ""
+		for {
+			var resp struct {
+				Parameters []struct {
+					Name  string `json:"Name"`
+					Value string `json:"Value"`
+				} `json:"Parameters"`
+				NextToken string `json:"NextToken"`
+			}
+			req := map[string]any{"Path": prefix, "Recursive": true, "WithDecryption": p.opts.decrypt}
+			if token != "" {
+				req["NextToken"] = token
+			}
+			if err := p.opts.call(ctx, creds, "ssm", "AmazonSSM.GetParametersByPath", req, &resp); err != nil {
+				return nil, fmt.Errorf("awsparams provider: get parameters by path %s: %w", prefix, err)
+			}
+			for _, param := range resp.Parameters {
+				if err := insertPath(doc, strings.TrimPrefix(param.Name, prefix), param.Value); err != nil {
+					return nil, fmt.Errorf("awsparams provider: parameter %s: %w", param.Name, err)
+				}
+			}
+			if token = resp.NextToken; token == "" {
+				break
+			}
+		}
+		return json.Marshal(doc)
+	}
+	return p
+}
+
+// NewSecret creates a provider that reads the current value of a Secrets
+// Manager secret, given by name or ARN. Binary secrets are returned decoded.
+func NewSecret(id string, opts ...Option) *Provider {
+	p := newProvider(opts)
+	p.read = func(ctx context.Context, creds Credentials) ([]byte, error) {
+		var resp struct {
+			SecretString *string `json:"SecretString"`
+			SecretBinary []byte  `json:"SecretBinary"`
+		}
+		req := map[string]any{"SecretId": id}
+		if err := p.opts.call(ctx, creds, "secretsmanager", "secretsmanager.GetSecretValue", req, &resp); err != nil {
+			return nil, fmt.Errorf("awsparams provider: get secret %s: %w", id, err)
+		}
+		if resp.SecretString != nil {
+			return []byte(*resp.SecretString), nil
+		}
+		return resp.SecretBinary, nil
+	}
+	return p
+}
+
+// Read implements provider.Provider.
+func (p *Provider) Read(ctx context.Context) ([]byte, error) {
+	if p.opts.region == "" {
+		return nil, fmt.Errorf("awsparams provider: region not set; use WithRegion or AWS_REGION")
+	}
+	creds, err := p.opts.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p.read(ctx, creds)
+}
+
+// insertPath stores value in doc under the "/"-separated rel path.
+func insertPath(doc map[string]any, rel, value string) error {
+	parts := strings.Split(strings.Trim(rel, "/"), "/")
+	if len(parts) == 1 && parts[0] == "" {
+		return fmt.Errorf("parameter equals the path prefix")
+	}
+	m := doc
+	for _, part := range parts[:len(parts)-1] {
+		switch next := m[part].(type) {
+		case nil:
+			child := map[string]any{}
+			m[part] = child
+			m = child
+		case map[string]any:
+			m = next
+		default:
+			return fmt.Errorf("%q is both a value and a path", part)
+		}
+	}
+	last := parts[len(parts)-1]
+	if _, exists := m[last]; exists {
+		return fmt.Errorf("%q is both a value and a path", last)
+	}
+	m[last] = value
+	return nil
+}
+
+// awsError is the error document returned by the JSON protocol services
+// (SSM, Secrets Manager) and, as <ErrorResponse><Error>, by the query
+// protocol services (STS).
+type awsError struct {
+	Type    string `json:"__type" xml:"Error>Code"`
+	Message string `json:"message" xml:"Error>Message"`
+}
+
+// call invokes a JSON-protocol AWS API and decodes the response into out.
+func (o *options) call(ctx context.Context, creds Credentials, service, target string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+	data, err := o.do(ctx, service, creds, body, func(req *http.Request) {
+		req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+		req.Header.Set("X-Amz-Target", target)
+	})
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// do sends a signed POST request to service and returns the response body.
+// Non-2xx responses are turned into errors; not-found errors wrap ErrNotFound.
+func (o *options) do(ctx context.Context, service string, creds Credentials, body []byte, prepare func(*http.Request)) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.endpoint(service, o.region), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	prepare(req)
+	sign(req, body, creds, o.region, service, time.Now())
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return data, nil
+	}
+	var e awsError
+	if json.Unmarshal(data, &e) != nil {
+		_ = xml.Unmarshal(data, &e)
+	}
+	// __type may be qualified, e.g. "com.amazonaws.ssm#ParameterNotFound".
+	if i := strings.LastIndexByte(e.Type, '#'); i >= 0 {
+		e.Type = e.Type[i+1:]
+	}
+	switch e.Type {
+	case "ParameterNotFound", "ResourceNotFoundException":
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, e.Message)
+	case "":
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil, fmt.Errorf("%s: %s (status %d)", e.Type, e.Message, resp.StatusCode)
+}
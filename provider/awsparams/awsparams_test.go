@@ -0,0 +1,245 @@
+package awsparams
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-sphere/confstore/provider"
+	"github.com/go-sphere/confstore/provider/providertest"
+)
+
+type fakeAWS struct {
+	mu      sync.Mutex
+	params  map[string]string
+	secrets map[string]any // string or []byte
+	keys    []string       // access key IDs seen on ssm/secretsmanager requests
+}
+
+func (f *fakeAWS) handler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/sts" {
+			_, _ = io.WriteString(w, `<AssumeRoleResponse><AssumeRoleResult><Credentials>
+<AccessKeyId>ASIAROLE</AccessKeyId><SecretAccessKey>rs</SecretAccessKey><SessionToken>tok</SessionToken>
+<Expiration>2999-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleResult></AssumeRoleResponse>`)
+			return
+		}
+		f.mu.Lock()
+		f.keys = append(f.keys, strings.SplitN(strings.TrimPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="), "/", 2)[0])
+		f.mu.Unlock()
+		var req map[string]any
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		notFound := func() {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"__type":"com.amazonaws.ssm#ParameterNotFound","message":"nope"}`)
+		}
+		switch r.Header.Get("X-Amz-Target") {
+		case "AmazonSSM.GetParameter":
+			v, ok := f.params[req["Name"].(string)]
+			if !ok {
+				notFound()
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"Parameter": map[string]any{"Value": v}})
+		case "AmazonSSM.GetParametersByPath":
+			// Serve one parameter per page to exercise pagination.
+			var names []string
+			for name := range f.params {
+				if strings.HasPrefix(name, req["Path"].(string)+"/") {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			start := 0
+			if tok, ok := req["NextToken"].(string); ok {
+				for i, n := range names {
+					if n == tok {
+						start = i
+					}
+				}
+			}
+			resp := map[string]any{"Parameters": []any{}}
+			if start < len(names) {
+				resp["Parameters"] = []any{map[string]any{"Name": names[start], "Value": f.params[names[start]]}}
+				if start+1 < len(names) {
+					resp["NextToken"] = names[start+1]
+				}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case "secretsmanager.GetSecretValue":
+			switch v := f.secrets[req["SecretId"].(string)].(type) {
+			case string:
+				_ = json.NewEncoder(w).Encode(map[string]any{"SecretString": v})
+			case []byte:
+				_ = json.NewEncoder(w).Encode(map[string]any{"SecretBinary": v})
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = io.WriteString(w, `{"__type":"ResourceNotFoundException","message":"nope"}`)
+			}
+		default:
+			t.Errorf("unexpected target %q", r.Header.Get("X-Amz-Target"))
+		}
+	})
+}
+
+func testOptions(url string) []Option {
+	return []Option{
+		WithRegion("us-east-1"),
+		WithCredentials(StaticCredentials("AKID", "secret", "")),
+		WithEndpoint(func(service, _ string) string { return url + "/" + service }),
+	}
+}
+
+func TestParameter(t *testing.T) {
+	f := &fakeAWS{params: map[string]string{"/app/config": `{"a":1}`}}
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	got, err := NewParameter("/app/config", testOptions(srv.URL)...).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if string(got) != `{"a":1}` {
+		t.Fatalf("unexpected value: %s", got)
+	}
+	_, err = NewParameter("/missing", testOptions(srv.URL)...).Read(context.Background())
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestParameterPath(t *testing.T) {
+	f := &fakeAWS{params: map[string]string{
+		"/app/prod/addr":    "0.0.0.0:8080",
+		"/app/prod/db/host": "db.internal",
+		"/app/prod/db/port": "5432",
+		"/app/dev/addr":     "localhost",
+	}}
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	got, err := NewParameterPath("/app/prod/", testOptions(srv.URL)...).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	want := `{"addr":"0.0.0.0:8080","db":{"host":"db.internal","port":"5432"}}`
+	if string(got) != want {
+		t.Fatalf("unexpected document:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestInsertPathConflict(t *testing.T) {
+	doc := map[string]any{}
+	if err := insertPath(doc, "/a", "1"); err != nil {
+		t.Fatalf("insertPath error: %v", err)
+	}
+	if err := insertPath(doc, "/a/b", "2"); err == nil {
+		t.Fatalf("expected conflict error")
+	}
+}
+
+func TestSecret(t *testing.T) {
+	f := &fakeAWS{secrets: map[string]any{"app": `{"password":"x"}`, "bin": []byte{0, 1, 2}}}
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	got, err := NewSecret("app", testOptions(srv.URL)...).Read(context.Background())
+	if err != nil || string(got) != `{"password":"x"}` {
+		t.Fatalf("unexpected secret %q, err %v", got, err)
+	}
+	got, err = NewSecret("bin", testOptions(srv.URL)...).Read(context.Background())
+	if err != nil || string(got) != "\x00\x01\x02" {
+		t.Fatalf("unexpected binary secret %q, err %v", got, err)
+	}
+	_, err = NewSecret("missing", testOptions(srv.URL)...).Read(context.Background())
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAssumeRole(t *testing.T) {
+	f := &fakeAWS{params: map[string]string{"p": "v"}}
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	opts := append(testOptions(srv.URL), WithAssumeRole("arn:aws:iam::123:role/app", "test"))
+	p := NewParameter("p", opts...)
+	for i := 0; i < 2; i++ {
+		if _, err := p.Read(context.Background()); err != nil {
+			t.Fatalf("Read error: %v", err)
+		}
+	}
+	for _, k := range f.keys {
+		if k != "ASIAROLE" {
+			t.Fatalf("expected requests signed with role credentials, got %q", k)
+		}
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		if r.URL.Path == "/sts" {
+			_, _ = io.WriteString(w, `<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code>
+<Message>not authorized to assume role</Message></Error><RequestId>r</RequestId></ErrorResponse>`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"__type":"AccessDeniedException","Message":"no ssm:GetParameter"}`)
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"ssm", nil, "AccessDeniedException: no ssm:GetParameter (status 403)"},
+		{"sts", []Option{WithAssumeRole("arn:aws:iam::123:role/app", "test")}, "AccessDenied: not authorized to assume role (status 403)"},
+	} {
+		_, err := NewParameter("p", append(testOptions(srv.URL), tc.opts...)...).Read(context.Background())
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestMissingCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	_, err := NewParameter("p", WithRegion("us-east-1")).Read(context.Background())
+	if !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	serve := func(t *testing.T, secrets map[string]any) string {
+		srv := httptest.NewServer((&fakeAWS{secrets: secrets}).handler(t))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+	providertest.TestProvider(t, providertest.Harness{
+		New: func(t *testing.T, data []byte) provider.Provider {
+			// SecretBinary round-trips arbitrary bytes; parameter values are text.
+			return NewSecret("app", testOptions(serve(t, map[string]any{"app": data}))...)
+		},
+		Missing: func(t *testing.T) provider.Provider {
+			return NewSecret("missing", testOptions(serve(t, nil))...)
+		},
+		HonorsCancellation: true,
+	})
+}
@@ -0,0 +1,113 @@
+package awsparams
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials are the AWS credentials used to sign requests.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Expires is the time after which the credentials must be refreshed; zero means never.
+	Expires time.Time
+}
+
+// CredentialsFunc returns the credentials to sign a request with. It is called
+// for every request, so implementations should cache.
+type CredentialsFunc func(ctx context.Context) (Credentials, error)
+
+// StaticCredentials returns a CredentialsFunc that always yields the given keys.
+func StaticCredentials(accessKeyID, secretAccessKey, sessionToken string) CredentialsFunc {
+	c := Credentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, SessionToken: sessionToken}
+	return func(context.Context) (Credentials, error) { return c, nil }
+}
+
+// EnvCredentials reads AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN from the environment. It is the default credential source
+// and the only one consulted unless WithCredentials is given; instance,
+// container and web identity credentials are not supported.
+func EnvCredentials(context.Context) (Credentials, error) {
+	c := Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("%w: AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are not set", ErrNoCredentials)
+	}
+	return c, nil
+}
+
+// assumeRole returns a CredentialsFunc that exchanges base credentials for
+// temporary role credentials via STS AssumeRole, caching them until shortly
+// before they expire.
+func assumeRole(o *options, base CredentialsFunc) CredentialsFunc {
+	var (
+		mu     sync.Mutex
+		cached Credentials
+	)
+	return func(ctx context.Context) (Credentials, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if cached.AccessKeyID != "" && time.Now().Add(5*time.Minute).Before(cached.Expires) {
+			return cached, nil
+		}
+		creds, err := base(ctx)
+		if err != nil {
+			return Credentials{}, err
+		}
+		form := url.Values{
+			"Action":          {"AssumeRole"},
+			"Version":         {"2011-06-15"},
+			"RoleArn":         {o.roleARN},
+			"RoleSessionName": {o.roleSession},
+			"DurationSeconds": {"3600"},
+		}
+		body := []byte(form.Encode())
+		data, err := o.do(ctx, "sts", creds, body, func(req *http.Request) {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+		})
+		if err != nil {
+			return Credentials{}, fmt.Errorf("awsparams provider: assume role %s: %w", o.roleARN, err)
+		}
+		var resp struct {
+			Credentials struct {
+				AccessKeyID     string    `xml:"AccessKeyId"`
+				SecretAccessKey string    `xml:"SecretAccessKey"`
+				SessionToken    string    `xml:"SessionToken"`
+				Expiration      time.Time `xml:"Expiration"`
+			} `xml:"AssumeRoleResult>Credentials"`
+		}
+		if err = xml.Unmarshal(data, &resp); err != nil {
+			return Credentials{}, fmt.Errorf("awsparams provider: assume role %s: decode response: %w", o.roleARN, err)
+		}
+		if resp.Credentials.AccessKeyID == "" {
+			return Credentials{}, fmt.Errorf("awsparams provider: assume role %s: response has no credentials", o.roleARN)
+		}
+		cached = Credentials{
+			AccessKeyID:     resp.Credentials.AccessKeyID,
+			SecretAccessKey: resp.Credentials.SecretAccessKey,
+			SessionToken:    resp.Credentials.SessionToken,
+			Expires:         resp.Credentials.Expiration,
+		}
+		return cached, nil
+	}
+}
+
+func defaultRegion() string {
+	for _, k := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if r := strings.TrimSpace(os.Getenv(k)); r != "" {
+			return r
+		}
+	}
+	return ""
+}
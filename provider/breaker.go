@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a CircuitBreaker that is short-circuiting reads.
+var ErrCircuitOpen = errors.New("provider: circuit breaker open")
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState int
+
+const (
+	// BreakerClosed passes reads through to the wrapped provider.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails reads immediately without calling the wrapped provider.
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial read through to probe for recovery.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// CircuitBreaker wraps a provider and stops calling it after consecutive
+// failures, so a down config server is not hammered by retries and startup
+// paths fail fast. After the open timeout one trial read is let through: on
+// success the breaker closes, on failure it stays open for another timeout.
+// Reads that fail because the caller's context ended do not count as failures.
+type CircuitBreaker struct {
+	provider Provider
+	opts     *breakerOptions
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool              // a half-open trial read is in flight
+	last     map[string][]byte // last good document per valuesID
+}
+
+type breakerOptions struct {
+	threshold   int
+	openTimeout time.Duration
+	serveStale  bool
+	onState     func(BreakerState)
+}
+
+// BreakerOption configures a CircuitBreaker.
+type BreakerOption func(*breakerOptions)
+
+// WithFailureThreshold sets the number of consecutive failures that open the breaker. Default: 5.
+func WithFailureThreshold(n int) BreakerOption { return func(o *breakerOptions) { o.threshold = n } }
+
+// WithOpenTimeout sets how long the breaker stays open before a trial read. Default: 30s.
+func WithOpenTimeout(d time.Duration) BreakerOption {
+	return func(o *breakerOptions) { o.openTimeout = d }
+}
+
+// WithServeStale makes the breaker return the last successfully read
+// configuration instead of ErrCircuitOpen while open, if there is one. The
+// stale copy is kept per set of context values (see WithValues), so a caller
+// only ever gets a document read for the same values.
+func WithServeStale() BreakerOption { return func(o *breakerOptions) { o.serveStale = true } }
+
+// WithStateChange sets a callback invoked on every state transition, e.g. for
+// metrics or logging. It is called with the breaker's lock held and must not
+// call back into the breaker.
+func WithStateChange(f func(BreakerState)) BreakerOption {
+	return func(o *breakerOptions) { o.onState = f }
+}
+
+// NewCircuitBreaker wraps p in a CircuitBreaker.
+func NewCircuitBreaker(p Provider, opts ...BreakerOption) *CircuitBreaker {
+	o := &breakerOptions{threshold: 5, openTimeout: 30 * time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.threshold < 1 {
+		o.threshold = 1
+	}
+	return &CircuitBreaker{provider: p, opts: o, last: make(map[string][]byte)}
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.currentState(time.Now())
+}
+
+// Read implements Provider.
+func (b *CircuitBreaker) Read(ctx context.Context) ([]byte, error) {
+	key := valuesID(Values(ctx))
+	b.mu.Lock()
+	switch b.currentState(time.Now()) {
+	case BreakerOpen:
+		defer b.mu.Unlock()
+		return b.rejected(key)
+	case BreakerHalfOpen:
+		if b.trial {
+			defer b.mu.Unlock()
+			return b.rejected(key)
+		}
+		b.trial = true
+	}
+	b.mu.Unlock()
+
+	data, err := b.provider.Read(ctx)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	switch {
+	case err == nil:
+		b.failures = 0
+		if b.opts.serveStale {
+			b.last[key] = bytes.Clone(data)
+		}
+		b.setState(BreakerClosed)
+	case ctx.Err() != nil:
+		// Caller gave up; says nothing about the provider's health.
+	default:
+		b.failures++
+		if b.state == BreakerHalfOpen || b.failures >= b.opts.threshold {
+			b.openedAt = time.Now()
+			b.setState(BreakerOpen)
+		}
+	}
+	return data, err
+}
+
+// currentState moves an open breaker to half-open once the timeout elapsed.
+func (b *CircuitBreaker) currentState(now time.Time) BreakerState {
+	if b.state == BreakerOpen && now.Sub(b.openedAt) >= b.opts.openTimeout {
+		b.setState(BreakerHalfOpen)
+	}
+	return b.state
+}
+
+func (b *CircuitBreaker) setState(s BreakerState) {
+	if b.state == s {
+		return
+	}
+	b.state = s
+	if b.opts.onState != nil {
+		b.opts.onState(s)
+	}
+}
+
+func (b *CircuitBreaker) rejected(key string) ([]byte, error) {
+	if last, ok := b.last[key]; ok {
+		return bytes.Clone(last), nil
+	}
+	return nil, ErrCircuitOpen
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	var calls int
+	fail := true
+	p := ReaderFunc(func(ctx context.Context) ([]byte, error) {
+		calls++
+		if fail {
+			return nil, errors.New("down")
+		}
+		return []byte("ok"), nil
+	})
+	var states []BreakerState
+	b := NewCircuitBreaker(p, WithFailureThreshold(2), WithOpenTimeout(20*time.Millisecond),
+		WithStateChange(func(s BreakerState) { states = append(states, s) }))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := b.Read(ctx); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("read %d: expected provider error, got %v", i, err)
+		}
+	}
+	if _, err := b.Read(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("open breaker called provider: %d calls", calls)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if b.State() != BreakerHalfOpen {
+		t.Fatalf("expected half-open, got %v", b.State())
+	}
+	if _, err := b.Read(ctx); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("trial read: expected provider error, got %v", err)
+	}
+	if b.State() != BreakerOpen {
+		t.Fatalf("failed trial should reopen, got %v", b.State())
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	fail = false
+	if data, err := b.Read(ctx); err != nil || string(data) != "ok" {
+		t.Fatalf("trial read = %q, %v", data, err)
+	}
+	want := []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	if len(states) != len(want) {
+		t.Fatalf("states = %v, want %v", states, want)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Fatalf("states = %v, want %v", states, want)
+		}
+	}
+}
+
+func TestCircuitBreaker_ServeStale(t *testing.T) {
+	fail := false
+	p := ReaderFunc(func(ctx context.Context) ([]byte, error) {
+		if fail {
+			return nil, errors.New("down")
+		}
+		return []byte("cached"), nil
+	})
+	b := NewCircuitBreaker(p, WithFailureThreshold(1), WithOpenTimeout(time.Hour), WithServeStale())
+	if _, err := b.Read(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fail = true
+	_, _ = b.Read(context.Background())
+	data, err := b.Read(context.Background())
+	if err != nil || string(data) != "cached" {
+		t.Fatalf("expected stale data, got %q, %v", data, err)
+	}
+}
+
+func TestCircuitBreaker_ServeStalePerTenant(t *testing.T) {
+	fail := false
+	p := ReaderFunc(func(ctx context.Context) ([]byte, error) {
+		if fail {
+			return nil, errors.New("down")
+		}
+		tenant, _ := Value(ctx, "tenant")
+		return []byte(tenant), nil
+	})
+	acme := WithValues(context.Background(), map[string]string{"tenant": "acme"})
+	globex := WithValues(context.Background(), map[string]string{"tenant": "globex"})
+	b := NewCircuitBreaker(p, WithFailureThreshold(1), WithOpenTimeout(time.Hour), WithServeStale())
+	if _, err := b.Read(acme); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fail = true
+	_, _ = b.Read(acme)
+	if data, err := b.Read(acme); err != nil || string(data) != "acme" {
+		t.Fatalf("expected acme's stale data, got %q, %v", data, err)
+	}
+	if data, err := b.Read(globex); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("globex must not get another tenant's data, got %q, %v", data, err)
+	}
+}
+
+func TestCircuitBreaker_IgnoresCallerCancellation(t *testing.T) {
+	p := ReaderFunc(func(ctx context.Context) ([]byte, error) { return nil, ctx.Err() })
+	b := NewCircuitBreaker(p, WithFailureThreshold(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = b.Read(ctx)
+	if b.State() != BreakerClosed {
+		t.Fatalf("caller cancellation opened the breaker")
+	}
+}
+
+func TestWithReadTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	p := WithReadTimeout(ReaderFunc(func(ctx context.Context) ([]byte, error) {
+		<-block // ignores ctx, like a hung filesystem read
+		return nil, nil
+	}), 10*time.Millisecond)
+	start := time.Now()
+	_, err := p.Read(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("timeout not enforced")
+	}
+
+	fast := WithReadTimeout(ReaderFunc(func(ctx context.Context) ([]byte, error) { return []byte("x"), nil }), time.Second)
+	if data, err := fast.Read(context.Background()); err != nil || string(data) != "x" {
+		t.Fatalf("fast read = %q, %v", data, err)
+	}
+}
+
+func TestWithReadTimeoutNonPositive(t *testing.T) {
+	m := NewMemory([]byte("x"))
+	for _, d := range []time.Duration{0, -time.Second} {
+		if p := WithReadTimeout(m, d); p != Provider(m) {
+			t.Fatalf("WithReadTimeout(%v) should return the provider unchanged", d)
+		}
+	}
+}
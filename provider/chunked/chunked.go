@@ -0,0 +1,247 @@
+// Package chunked provides a differential-sync provider for large
+// configuration bundles on bandwidth-constrained links.
+//
+// The publisher splits the artifact with Split and serves a JSON Manifest plus
+// one file per chunk. On each Read the provider fetches only the manifest and
+// the chunks it does not already hold, then reassembles and verifies the
+// artifact, similar in spirit to rsync.
+package chunked
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	// ErrIntegrity indicates that a chunk or the reassembled artifact does not match its manifest hash.
+	ErrIntegrity = errors.New("chunked provider: integrity check failed")
+	// ErrInvalidManifest indicates a manifest with malformed hashes or sizes.
+	ErrInvalidManifest = errors.New("chunked provider: invalid manifest")
+	// ErrTooLarge indicates an artifact or response exceeding the configured max size.
+	ErrTooLarge = errors.New("chunked provider: artifact too large")
+)
+
+// manifestOverhead is how much larger than the artifact limit a manifest may
+// be. Every chunk but the last holds at least minChunkSize bytes and costs
+// about a hundred bytes of manifest, so only tiny artifacts come close.
+const manifestOverhead = 64 << 10
+
+// DefaultMaxSize is the artifact size limit used unless WithMaxSize is given.
+const DefaultMaxSize = 1 << 30
+
+// Chunked fetches a manifest and the chunks that changed since the previous Read.
+// It is safe for concurrent use.
+type Chunked struct {
+	manifestURL string
+	opts        *options
+
+	mu    sync.Mutex
+	cache map[string][]byte // chunks of the last assembled artifact
+}
+
+type options struct {
+	client   *http.Client
+	chunkURL func(hash string) string
+	cacheDir string
+	maxSize  int64
+}
+
+// Option configures optional behavior for the chunked provider.
+type Option func(*options)
+
+// WithClient sets a custom HTTP client. Default: a client without timeout;
+// prefer controlling deadlines with the Read context.
+func WithClient(c *http.Client) Option { return func(o *options) { o.client = c } }
+
+// WithChunkURL overrides how chunk URLs are derived from their hash.
+// Default: "chunks/<hash>" resolved relative to the manifest URL.
+func WithChunkURL(f func(hash string) string) Option { return func(o *options) { o.chunkURL = f } }
+
+// WithCacheDir persists chunks in dir so that a restarted process does not
+// download them again. Chunks are verified against their hash when loaded.
+func WithCacheDir(dir string) Option { return func(o *options) { o.cacheDir = dir } }
+
+// WithMaxSize limits the artifact size in bytes, as declared by the manifest
+// and as downloaded; larger artifacts fail with ErrTooLarge before anything is
+// allocated for them. Default: DefaultMaxSize.
+func WithMaxSize(n int64) Option { return func(o *options) { o.maxSize = n } }
+
+// New creates a differential-sync provider for the manifest at manifestURL.
+func New(manifestURL string, opts ...Option) *Chunked {
+	o := &options{maxSize: DefaultMaxSize}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.client == nil {
+		o.client = &http.Client{}
+	}
+	if o.chunkURL == nil {
+		o.chunkURL = func(hash string) string {
+			base, err := url.Parse(manifestURL)
+			if err != nil {
+				return manifestURL + "/chunks/" + hash
+			}
+			return base.ResolveReference(&url.URL{Path: "chunks/" + hash}).String()
+		}
+	}
+	return &Chunked{manifestURL: manifestURL, opts: o, cache: make(map[string][]byte)}
+}
+
+// Read implements provider.Provider. It downloads the manifest, fetches missing
+// chunks, and returns the reassembled artifact after verifying its digest.
+func (c *Chunked) Read(ctx context.Context) ([]byte, error) {
+	limit := c.opts.maxSize
+	if limit > 0 {
+		limit += manifestOverhead
+	}
+	raw, err := c.get(ctx, c.manifestURL, limit)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err = json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("chunked provider: decode manifest %s: %w", c.manifestURL, err)
+	}
+	if err = c.validate(&m); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	next := make(map[string][]byte, len(m.Chunks))
+	buf := bytes.NewBuffer(make([]byte, 0, m.Size))
+	for _, ref := range m.Chunks {
+		chunk, err := c.chunk(ctx, ref, next)
+		if err != nil {
+			return nil, err
+		}
+		if int64(buf.Len()+len(chunk)) > m.Size {
+			return nil, fmt.Errorf("%w: artifact %s exceeds declared size", ErrIntegrity, c.manifestURL)
+		}
+		next[ref.Hash] = chunk
+		buf.Write(chunk)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	if hex.EncodeToString(sum[:]) != m.SHA256 || int64(buf.Len()) != m.Size {
+		return nil, fmt.Errorf("%w: artifact %s", ErrIntegrity, c.manifestURL)
+	}
+	// Keep only the chunks referenced by the current manifest.
+	c.cache = next
+	return buf.Bytes(), nil
+}
+
+// validate checks the manifest before any of its values are used for
+// allocation, file names or URLs.
+func (c *Chunked) validate(m *Manifest) error {
+	if m.Size < 0 {
+		return fmt.Errorf("%w: %s: negative size %d", ErrInvalidManifest, c.manifestURL, m.Size)
+	}
+	if c.opts.maxSize > 0 && m.Size > c.opts.maxSize {
+		return fmt.Errorf("%w: %s: size %d exceeds limit %d", ErrTooLarge, c.manifestURL, m.Size, c.opts.maxSize)
+	}
+	if !isHash(m.SHA256) {
+		return fmt.Errorf("%w: %s: malformed artifact hash", ErrInvalidManifest, c.manifestURL)
+	}
+	var total int64
+	for _, ref := range m.Chunks {
+		if !isHash(ref.Hash) {
+			return fmt.Errorf("%w: %s: malformed chunk hash %q", ErrInvalidManifest, c.manifestURL, ref.Hash)
+		}
+		if ref.Size < 0 || ref.Size > m.Size-total {
+			return fmt.Errorf("%w: %s: chunk sizes do not add up to %d", ErrInvalidManifest, c.manifestURL, m.Size)
+		}
+		total += ref.Size
+	}
+	if total != m.Size {
+		return fmt.Errorf("%w: %s: chunk sizes do not add up to %d", ErrInvalidManifest, c.manifestURL, m.Size)
+	}
+	return nil
+}
+
+// isHash reports whether s is a lowercase hex SHA-256 digest, so it is safe
+// to use as a file name and URL path element.
+func isHash(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !('0' <= s[i] && s[i] <= '9' || 'a' <= s[i] && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// chunk returns a verified chunk from memory, the cache directory or the network.
+func (c *Chunked) chunk(ctx context.Context, ref ChunkRef, pending map[string][]byte) ([]byte, error) {
+	if data, ok := pending[ref.Hash]; ok {
+		return data, nil
+	}
+	if data, ok := c.cache[ref.Hash]; ok {
+		return data, nil
+	}
+	if c.opts.cacheDir != "" {
+		if data, err := os.ReadFile(filepath.Join(c.opts.cacheDir, ref.Hash)); err == nil && verify(data, ref.Hash) {
+			return data, nil
+		}
+	}
+	u := c.opts.chunkURL(ref.Hash)
+	data, err := c.get(ctx, u, c.opts.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	if !verify(data, ref.Hash) {
+		return nil, fmt.Errorf("%w: chunk %s", ErrIntegrity, u)
+	}
+	if c.opts.cacheDir != "" {
+		// Best effort: a failed cache write only costs a download next time.
+		_ = os.WriteFile(filepath.Join(c.opts.cacheDir, ref.Hash), data, 0o644)
+	}
+	return data, nil
+}
+
+// get downloads u, failing with ErrTooLarge beyond limit bytes unless limit is
+// non-positive.
+func (c *Chunked) get(ctx context.Context, u string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("chunked provider: build request GET %s: %w", u, err)
+	}
+	resp, err := c.opts.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("chunked provider: do request GET %s: %w", u, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("chunked provider: GET %s unexpected status %s", u, resp.Status)
+	}
+	var body io.Reader = resp.Body
+	if limit > 0 {
+		body = io.LimitReader(resp.Body, limit+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("chunked provider: read body GET %s: %w", u, err)
+	}
+	if limit > 0 && int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: GET %s exceeds limit %d", ErrTooLarge, u, limit)
+	}
+	return data, nil
+}
+
+func verify(data []byte, hash string) bool {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]) == hash
+}
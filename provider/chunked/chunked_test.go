@@ -0,0 +1,202 @@
+package chunked
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-sphere/confstore/provider"
+	"github.com/go-sphere/confstore/provider/providertest"
+)
+
+type publisher struct {
+	mu         sync.Mutex
+	manifest   []byte
+	chunks     map[string][]byte
+	chunkGets  int
+	corruptAll bool
+}
+
+func (p *publisher) publish(data []byte) {
+	m, chunks := Split(data)
+	raw, _ := json.Marshal(m)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.manifest = raw
+	p.chunks = chunks
+	p.chunkGets = 0
+}
+
+func (p *publisher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if r.URL.Path == "/bundle/manifest.json" {
+		_, _ = w.Write(p.manifest)
+		return
+	}
+	hash := strings.TrimPrefix(r.URL.Path, "/bundle/chunks/")
+	chunk, ok := p.chunks[hash]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	p.chunkGets++
+	if p.corruptAll {
+		chunk = append([]byte("x"), chunk...)
+	}
+	_, _ = w.Write(chunk)
+}
+
+func randomBundle(n int) []byte {
+	r := rand.New(rand.NewSource(1))
+	data := make([]byte, n)
+	r.Read(data)
+	return data
+}
+
+func TestChunkedDownloadsOnlyChangedChunks(t *testing.T) {
+	pub := &publisher{}
+	srv := httptest.NewServer(pub)
+	defer srv.Close()
+
+	v1 := randomBundle(2 << 20)
+	pub.publish(v1)
+	p := New(srv.URL + "/bundle/manifest.json")
+	got, err := p.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if !bytes.Equal(got, v1) {
+		t.Fatal("first read returned different bytes")
+	}
+	total := pub.chunkGets
+	if total < 4 {
+		t.Fatalf("expected artifact to be split into several chunks, got %d", total)
+	}
+
+	v2 := append([]byte(nil), v1...)
+	copy(v2[1<<20:], "a small edit in the middle of the bundle")
+	pub.publish(v2)
+	got, err = p.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if !bytes.Equal(got, v2) {
+		t.Fatal("second read returned different bytes")
+	}
+	if pub.chunkGets == 0 || pub.chunkGets > 2 {
+		t.Fatalf("expected 1-2 changed chunks to be downloaded, got %d of %d", pub.chunkGets, total)
+	}
+}
+
+func TestChunkedCacheDir(t *testing.T) {
+	pub := &publisher{}
+	srv := httptest.NewServer(pub)
+	defer srv.Close()
+	dir := t.TempDir()
+
+	data := randomBundle(512 << 10)
+	pub.publish(data)
+	if _, err := New(srv.URL+"/bundle/manifest.json", WithCacheDir(dir)).Read(context.Background()); err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	pub.publish(data)
+	// A fresh provider (e.g. after restart) reuses chunks from disk.
+	got, err := New(srv.URL+"/bundle/manifest.json", WithCacheDir(dir)).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if !bytes.Equal(got, data) || pub.chunkGets != 0 {
+		t.Fatalf("expected all chunks from cache dir, downloaded %d", pub.chunkGets)
+	}
+}
+
+func TestChunkedIntegrity(t *testing.T) {
+	pub := &publisher{corruptAll: true}
+	srv := httptest.NewServer(pub)
+	defer srv.Close()
+	pub.publish(randomBundle(64 << 10))
+	_, err := New(srv.URL + "/bundle/manifest.json").Read(context.Background())
+	if !errors.Is(err, ErrIntegrity) {
+		t.Fatalf("expected ErrIntegrity, got %v", err)
+	}
+}
+
+func serveManifest(t *testing.T, m Manifest) string {
+	t.Helper()
+	raw, _ := json.Marshal(m)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/manifest.json" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(raw)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL + "/manifest.json"
+}
+
+func TestChunkedRejectsBadManifest(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	cases := []struct {
+		name string
+		m    Manifest
+		want error
+	}{
+		{"negative size", Manifest{Size: -1, SHA256: hash}, ErrInvalidManifest},
+		{"huge size", Manifest{Size: 1 << 62, SHA256: hash, Chunks: []ChunkRef{{Hash: hash, Size: 1 << 62}}}, ErrTooLarge},
+		{"size mismatch", Manifest{Size: 10, SHA256: hash, Chunks: []ChunkRef{{Hash: hash, Size: 4}}}, ErrInvalidManifest},
+		{"path in hash", Manifest{Size: 1, SHA256: hash, Chunks: []ChunkRef{{Hash: "../../etc/passwd", Size: 1}}}, ErrInvalidManifest},
+	}
+	for _, tc := range cases {
+		_, err := New(serveManifest(t, tc.m), WithCacheDir(t.TempDir())).Read(context.Background())
+		if !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestChunkedMaxSize(t *testing.T) {
+	pub := &publisher{}
+	srv := httptest.NewServer(pub)
+	defer srv.Close()
+	pub.publish(randomBundle(64 << 10))
+	_, err := New(srv.URL+"/bundle/manifest.json", WithMaxSize(32<<10)).Read(context.Background())
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+
+	// The manifest of a tiny artifact outgrows the artifact itself; it must
+	// not count against the artifact limit.
+	pub.publish([]byte("tiny"))
+	got, err := New(srv.URL+"/bundle/manifest.json", WithMaxSize(4)).Read(context.Background())
+	if err != nil || string(got) != "tiny" {
+		t.Fatalf("Read at the limit = %q, %v", got, err)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	serve := func(t *testing.T, data []byte) string {
+		pub := &publisher{}
+		pub.publish(data)
+		srv := httptest.NewServer(pub)
+		t.Cleanup(srv.Close)
+		return srv.URL + "/bundle/manifest.json"
+	}
+	providertest.TestProvider(t, providertest.Harness{
+		New: func(t *testing.T, data []byte) provider.Provider { return New(serve(t, data)) },
+		Limited: func(t *testing.T, data []byte, limit int64) provider.Provider {
+			return New(serve(t, data), WithMaxSize(limit))
+		},
+		LimitErr:           ErrTooLarge,
+		HonorsCancellation: true,
+	})
+}
@@ -0,0 +1,81 @@
+package chunked
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Chunk boundaries are content-defined with a gear rolling hash, so an edit
+// only changes the chunks around it and the rest of the artifact keeps the
+// same chunk hashes between versions.
+const (
+	minChunkSize = 16 << 10
+	avgChunkMask = 1<<16 - 1 // ~64KiB average chunk size
+	maxChunkSize = 256 << 10
+)
+
+var gear = func() (table [256]uint64) {
+	// splitmix64 with a fixed seed keeps chunk boundaries stable across builds.
+	x := uint64(0x9E3779B97F4A7C15)
+	for i := range table {
+		x += 0x9E3779B97F4A7C15
+		z := x
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// Manifest describes an artifact as an ordered list of content-addressed chunks.
+type Manifest struct {
+	// Size is the total artifact size in bytes.
+	Size int64 `json:"size"`
+	// SHA256 is the hex-encoded digest of the whole artifact.
+	SHA256 string `json:"sha256"`
+	// Chunks lists the chunks in artifact order.
+	Chunks []ChunkRef `json:"chunks"`
+}
+
+// ChunkRef identifies a chunk by the hex-encoded SHA-256 of its content.
+type ChunkRef struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// Split cuts data into content-defined chunks and returns the manifest along
+// with the chunk contents keyed by hash. Publishers serve the manifest and
+// each chunk (by default at "chunks/<hash>" next to the manifest).
+func Split(data []byte) (*Manifest, map[string][]byte) {
+	sum := sha256.Sum256(data)
+	m := &Manifest{Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}
+	chunks := make(map[string][]byte)
+	for len(data) > 0 {
+		n := nextBoundary(data)
+		chunk := data[:n]
+		h := sha256.Sum256(chunk)
+		hash := hex.EncodeToString(h[:])
+		m.Chunks = append(m.Chunks, ChunkRef{Hash: hash, Size: int64(n)})
+		chunks[hash] = chunk
+		data = data[n:]
+	}
+	return m, chunks
+}
+
+func nextBoundary(data []byte) int {
+	if len(data) <= minChunkSize {
+		return len(data)
+	}
+	limit := len(data)
+	if limit > maxChunkSize {
+		limit = maxChunkSize
+	}
+	var h uint64
+	for i := minChunkSize; i < limit; i++ {
+		h = (h << 1) + gear[data[i]]
+		if h&avgChunkMask == 0 {
+			return i + 1
+		}
+	}
+	return limit
+}
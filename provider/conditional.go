@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// ErrConflict indicates that a conditional write was rejected because the
+// source changed since the expected version was read.
+var ErrConflict = errors.New("provider: write conflict")
+
+// ConditionalWriter is implemented by writable providers that support
+// optimistic concurrency, so that several processes editing a shared config
+// do not silently overwrite each other's changes.
+type ConditionalWriter interface {
+	Provider
+	// ReadVersion returns the configuration together with an opaque token
+	// identifying its current version.
+	ReadVersion(ctx context.Context) (data []byte, version string, err error)
+	// WriteIf replaces the configuration with data only if its current version
+	// equals expected, and returns the new version. An empty expected version
+	// requires that the source does not exist yet. On mismatch the error wraps
+	// ErrConflict and nothing is written.
+	WriteIf(ctx context.Context, data []byte, expected string) (version string, err error)
+}
+
+// RetryWithMerge performs an optimistic read-modify-write on w. It reads the
+// current configuration, passes it to merge and writes the result back
+// conditionally; on conflict it re-reads and merges again, up to maxAttempts
+// times in total. A missing source is passed to merge as nil. merge must
+// therefore be safe to call repeatedly and should apply the caller's change
+// on top of whatever it is given rather than replacing it wholesale.
+func RetryWithMerge(ctx context.Context, w ConditionalWriter, maxAttempts int, merge func(current []byte) ([]byte, error)) (version string, err error) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		current, expected, err := w.ReadVersion(ctx)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		if err != nil {
+			current, expected = nil, ""
+		}
+		next, err := merge(current)
+		if err != nil {
+			return "", err
+		}
+		version, err = w.WriteIf(ctx, next, expected)
+		if !errors.Is(err, ErrConflict) {
+			return version, err
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+	}
+	return "", fmt.Errorf("%w: gave up after %d attempts", ErrConflict, maxAttempts)
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strconv"
+	"testing"
+)
+
+// memConditional is a ConditionalWriter whose version is a write counter. If
+// interfere is set, it is called before each conditional write to simulate a
+// competing writer.
+type memConditional struct {
+	data      []byte
+	version   int
+	exists    bool
+	interfere func(m *memConditional)
+}
+
+func (m *memConditional) Read(ctx context.Context) ([]byte, error) {
+	data, _, err := m.ReadVersion(ctx)
+	return data, err
+}
+
+func (m *memConditional) ReadVersion(context.Context) ([]byte, string, error) {
+	if !m.exists {
+		return nil, "", fs.ErrNotExist
+	}
+	return m.data, strconv.Itoa(m.version), nil
+}
+
+func (m *memConditional) WriteIf(_ context.Context, data []byte, expected string) (string, error) {
+	if m.interfere != nil {
+		m.interfere(m)
+	}
+	current := ""
+	if m.exists {
+		current = strconv.Itoa(m.version)
+	}
+	if current != expected {
+		return "", ErrConflict
+	}
+	m.data, m.exists = data, true
+	m.version++
+	return strconv.Itoa(m.version), nil
+}
+
+func TestRetryWithMerge(t *testing.T) {
+	m := &memConditional{}
+	interfered := 0
+	m.interfere = func(m *memConditional) {
+		if interfered < 2 {
+			interfered++
+			m.data, m.exists = append(m.data, 'x'), true
+			m.version++
+		}
+	}
+	version, err := RetryWithMerge(context.Background(), m, 5, func(current []byte) ([]byte, error) {
+		return append(append([]byte(nil), current...), 'y'), nil
+	})
+	if err != nil {
+		t.Fatalf("RetryWithMerge error: %v", err)
+	}
+	if string(m.data) != "xxy" || version != "3" {
+		t.Fatalf("unexpected result data=%q version=%q", m.data, version)
+	}
+}
+
+func TestRetryWithMergeGivesUp(t *testing.T) {
+	m := &memConditional{exists: true}
+	m.interfere = func(m *memConditional) { m.version++ }
+	_, err := RetryWithMerge(context.Background(), m, 3, func(current []byte) ([]byte, error) { return current, nil })
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
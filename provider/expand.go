@@ -34,9 +34,24 @@ func (e *ExpandEnv) Read(ctx context.Context) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	return expandEnv(data), nil
+}
+
+// ReadAt implements VersionedProvider by reading the given version from the
+// wrapped provider and expanding it like Read. It returns ErrVersionUnsupported
+// if the wrapped provider does not support versioned reads.
+func (e *ExpandEnv) ReadAt(ctx context.Context, version string) ([]byte, error) {
+	data, err := ReadAt(ctx, e.provider, version)
+	if err != nil {
+		return nil, err
+	}
+	return expandEnv(data), nil
+}
+
+func expandEnv(data []byte) []byte {
 	if len(data) == 0 || bytes.IndexByte(data, '$') == -1 {
-		return data, nil
+		return data
 	}
 	expandedData := os.ExpandEnv(string(data))
-	return []byte(expandedData), nil
+	return []byte(expandedData)
 }
@@ -1,14 +1,36 @@
 package file
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
+
+	"github.com/go-sphere/confstore/provider"
 )
 
+func init() {
+	provider.Register("file", open)
+}
+
+// open builds a File from a "file" URI such as "file:///etc/app.json" or a
+// scheme-less path. Only local hosts are accepted.
+func open(_ context.Context, u *url.URL) (provider.Provider, error) {
+	path, err := fileURLPath(u)
+	if err != nil {
+		return nil, err
+	}
+	return New(path), nil
+}
+
 // File provides configuration bytes loaded from a file on disk or any fs.FS.
 // Required: a file path. Optional: supply a custom fs, expand env vars in path, trim UTF-8 BOM.
 type File struct {
@@ -26,7 +48,9 @@ type options struct {
 type Option func(*options)
 
 // WithFS sets a custom filesystem to read from. When provided, the path is
-// interpreted relative to that filesystem and read via fs.ReadFile.
+// interpreted relative to that filesystem and read via fs.ReadFile. Backslashes
+// are treated as separators and leading slashes are dropped, so the same path
+// string works regardless of the OS it was written on.
 func WithFS(fsys fs.FS) Option { return func(o *options) { o.fsys = fsys } }
 
 // WithExpandEnv enables environment-variable expansion in the provided path
@@ -51,16 +75,17 @@ func New(path string, opts ...Option) *File {
 }
 
 // Read loads the file contents and returns the raw bytes.
-func (f *File) Read(_ context.Context) ([]byte, error) {
-	path := f.path
-	if f.opts.expandEnv {
-		path = os.ExpandEnv(path)
+// The path may also be given as a "file:" URL. Paths are normalized for the
+// target filesystem; see WithFS for how they are interpreted on an fs.FS.
+// "{key}" placeholders are filled from provider.Values(ctx); each value must
+// be a single path element.
+func (f *File) Read(ctx context.Context) ([]byte, error) {
+	path, err := f.resolvePath(ctx, f.opts.fsys != nil)
+	if err != nil {
+		return nil, err
 	}
 
-	var (
-		data []byte
-		err  error
-	)
+	var data []byte
 	if f.opts.fsys != nil {
 		data, err = fs.ReadFile(f.opts.fsys, path)
 	} else {
@@ -79,12 +104,159 @@ func (f *File) Read(_ context.Context) ([]byte, error) {
 	return data, nil
 }
 
+// Open implements provider.StreamProvider. The path is resolved like in
+// Read, and WithTrimBOM is honored.
+func (f *File) Open(ctx context.Context) (io.ReadCloser, error) {
+	path, err := f.resolvePath(ctx, f.opts.fsys != nil)
+	if err != nil {
+		return nil, err
+	}
+	var file io.ReadCloser
+	if f.opts.fsys != nil {
+		file, err = f.opts.fsys.Open(path)
+	} else {
+		file, err = os.Open(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !f.opts.trimBOM {
+		return file, nil
+	}
+	br := bufio.NewReader(file)
+	if prefix, _ := br.Peek(3); bytes.Equal(prefix, []byte{0xEF, 0xBB, 0xBF}) {
+		_, _ = br.Discard(3)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{br, file}, nil
+}
+
+// Write implements provider.Writer by atomically replacing the file with data:
+// the content is written to a temporary file in the same directory and renamed
+// into place, so concurrent readers never observe a partial file. Writing is
+// not supported when a custom fs.FS is configured.
+func (f *File) Write(ctx context.Context, data []byte) error {
+	path, err := f.writePath(ctx)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, data)
+}
+
+// ReadVersion implements provider.ConditionalWriter. The version is the
+// SHA-256 of the file's bytes on disk (before WithTrimBOM is applied). Like
+// Write, it is not supported when a custom fs.FS is configured.
+func (f *File) ReadVersion(ctx context.Context) ([]byte, string, error) {
+	path, err := f.writePath(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	data := raw
+	if f.opts.trimBOM {
+		data = bytes.TrimPrefix(raw, []byte{0xEF, 0xBB, 0xBF})
+	}
+	return data, contentVersion(raw), nil
+}
+
+// WriteIf implements provider.ConditionalWriter. The check and the write are
+// made under a lock file next to the target ("<name>.lock"), so cooperating
+// processes using WriteIf cannot interleave; plain Write calls and other
+// programs editing the file bypass the lock but are still detected by the
+// version check of the next WriteIf.
+func (f *File) WriteIf(ctx context.Context, data []byte, expected string) (string, error) {
+	path, err := f.writePath(ctx)
+	if err != nil {
+		return "", err
+	}
+	unlock, err := lockFile(ctx, path+".lock")
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	current := ""
+	raw, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		current = contentVersion(raw)
+	case !errors.Is(err, fs.ErrNotExist):
+		return "", err
+	}
+	if current != expected {
+		return "", fmt.Errorf("%w: %s has version %q, expected %q", provider.ErrConflict, path, current, expected)
+	}
+	if err = writeAtomic(path, data); err != nil {
+		return "", err
+	}
+	return contentVersion(data), nil
+}
+
+// writePath resolves the on-disk path written by Write and WriteIf.
+func (f *File) writePath(ctx context.Context) (string, error) {
+	if f.opts.fsys != nil {
+		return "", errors.New("file provider: cannot write to a custom fs.FS")
+	}
+	return f.resolvePath(ctx, false)
+}
+
+// resolvePath applies env expansion, context values and normalization to the
+// configured path.
+func (f *File) resolvePath(ctx context.Context, forFS bool) (string, error) {
+	path := f.path
+	if f.opts.expandEnv {
+		path = os.ExpandEnv(path)
+	}
+	path, err := provider.ExpandValues(ctx, path, pathSegment)
+	if err != nil {
+		return "", fmt.Errorf("file provider: %w", err)
+	}
+	return normalizePath(path, forFS)
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if info, statErr := os.Stat(path); statErr == nil {
+		_ = os.Chmod(tmp.Name(), info.Mode().Perm())
+	} else {
+		_ = os.Chmod(tmp.Name(), 0o644)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func contentVersion(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // IsLocalPath reports whether the given path is a local filesystem path.
+// Windows drive-letter ("C:\app.json", "C:/app.json") and UNC paths are
+// recognized on every OS, as are "file:" URLs.
 func IsLocalPath(path string) bool {
 	if path == "" {
 		return false
 	}
-	if filepath.IsAbs(path) {
+	if filepath.IsAbs(path) || isDrivePath(path) || isUNCPath(path) {
 		return true
 	}
 	if u, err := url.Parse(path); err == nil && u.Scheme != "" {
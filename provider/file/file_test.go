@@ -0,0 +1,229 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-sphere/confstore/provider"
+	"github.com/go-sphere/confstore/provider/providertest"
+)
+
+func TestIsLocalPath(t *testing.T) {
+	cases := map[string]bool{
+		"":                         false,
+		"config.json":              true,
+		"./conf/app.json":          true,
+		"/etc/app.json":            true,
+		`C:\conf\app.json`:         true,
+		"c:/conf/app.json":         true,
+		`\\server\share\app.json`:  true,
+		"file:///etc/app.json":     true,
+		"https://example/app.json": false,
+		"s3://bucket/app.json":     false,
+	}
+	for in, want := range cases {
+		if got := IsLocalPath(in); got != want {
+			t.Errorf("IsLocalPath(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestNormalizePathForFS(t *testing.T) {
+	cases := map[string]string{
+		`conf\app.json`:             "conf/app.json",
+		"./conf/../conf/app.json":   "conf/app.json",
+		"/conf/app.json":            "conf/app.json",
+		"file:///conf/app.json":     "conf/app.json",
+		"file://localhost/app.json": "app.json",
+	}
+	for in, want := range cases {
+		got, err := normalizePath(in, true)
+		if err != nil {
+			t.Fatalf("normalizePath(%q) error: %v", in, err)
+		}
+		if got != want {
+			t.Errorf("normalizePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if _, err := normalizePath("file://remote/app.json", true); err == nil {
+		t.Fatal("expected error for non-local host")
+	}
+}
+
+func TestReadFSWithBackslashes(t *testing.T) {
+	fsys := fstest.MapFS{"conf/app.json": {Data: []byte("{}")}}
+	got, err := New(`conf\app.json`, WithFS(fsys)).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if string(got) != "{}" {
+		t.Fatalf("got %q", string(got))
+	}
+}
+
+func TestReadFileURL(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(p, []byte("ok"), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	u := "file://" + filepath.ToSlash(p)
+	if filepath.VolumeName(p) != "" {
+		u = "file:///" + filepath.ToSlash(p)
+	}
+	got, err := New(u).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if string(got) != "ok" {
+		t.Fatalf("got %q", string(got))
+	}
+}
+
+func TestWriteReplacesFile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(p, []byte("old"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	f := New(p)
+	if err := f.Write(context.Background(), []byte("new")); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	got, err := f.Read(context.Background())
+	if err != nil || string(got) != "new" {
+		t.Fatalf("Read = %q, %v", got, err)
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Fatalf("mode not preserved: %v", info.Mode())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(p))
+	if len(entries) != 1 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestWriteFSReadOnly(t *testing.T) {
+	if err := New("app.json", WithFS(fstest.MapFS{})).Write(context.Background(), []byte("x")); err == nil {
+		t.Fatal("expected error writing to fs.FS")
+	}
+}
+
+func TestWriteIfConflict(t *testing.T) {
+	dir := t.TempDir()
+	f := New(filepath.Join(dir, "app.json"))
+	ctx := context.Background()
+
+	v1, err := f.WriteIf(ctx, []byte("v1"), "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err = f.WriteIf(ctx, []byte("again"), ""); !errors.Is(err, provider.ErrConflict) {
+		t.Fatalf("expected conflict creating existing file, got %v", err)
+	}
+	data, version, err := f.ReadVersion(ctx)
+	if err != nil || string(data) != "v1" || version != v1 {
+		t.Fatalf("ReadVersion = %q, %q, %v", data, version, err)
+	}
+
+	// Another writer updates the file behind our back.
+	if err = f.Write(ctx, []byte("other")); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if _, err = f.WriteIf(ctx, []byte("v2"), v1); !errors.Is(err, provider.ErrConflict) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	got, _ := f.Read(ctx)
+	if string(got) != "other" {
+		t.Fatalf("conflicting write modified file: %q", got)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("lock or temporary files left behind: %v", entries)
+	}
+}
+
+func TestRetryWithMergeConcurrent(t *testing.T) {
+	f := New(filepath.Join(t.TempDir(), "counter"))
+	ctx := context.Background()
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := provider.RetryWithMerge(ctx, f, 100, func(current []byte) ([]byte, error) {
+				n, _ := strconv.Atoi(string(current))
+				return []byte(strconv.Itoa(n + 1)), nil
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("RetryWithMerge error: %v", err)
+		}
+	}
+	got, _ := f.Read(ctx)
+	if string(got) != strconv.Itoa(writers) {
+		t.Fatalf("lost updates: counter = %s, want %d", got, writers)
+	}
+}
+
+func TestReplicatorMirrorWithTrimBOM(t *testing.T) {
+	data := append([]byte{0xEF, 0xBB, 0xBF}, `{"addr":":80"}`...)
+	source := provider.ReaderFunc(func(ctx context.Context) ([]byte, error) { return data, nil })
+	mirror := New(filepath.Join(t.TempDir(), "mirror.json"), WithTrimBOM())
+	r := provider.NewReplicator(source, mirror)
+	if _, err := r.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync error: %v", err)
+	}
+	raw, err := os.ReadFile(mirror.path)
+	if err != nil || string(raw) != string(data) {
+		t.Fatalf("mirror = %q, %v; want source bytes", raw, err)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	providertest.TestProvider(t, providertest.Harness{
+		New: func(t *testing.T, data []byte) provider.Provider {
+			p := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(p, data, 0o600); err != nil {
+				t.Fatalf("write temp file: %v", err)
+			}
+			return New(p)
+		},
+		Missing: func(t *testing.T) provider.Provider {
+			return New(filepath.Join(t.TempDir(), "missing.json"))
+		},
+	})
+}
+
+func TestReadPathTemplate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "acme.json"), []byte("acme"), 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	f := New(filepath.Join(dir, "{tenant}.json"))
+	got, err := f.Read(provider.WithValues(context.Background(), map[string]string{"tenant": "acme"}))
+	if err != nil || string(got) != "acme" {
+		t.Fatalf("Read = %q, %v", got, err)
+	}
+	_, err = f.Read(provider.WithValues(context.Background(), map[string]string{"tenant": "../acme"}))
+	if !errors.Is(err, provider.ErrInvalidValue) {
+		t.Fatalf("expected ErrInvalidValue for traversal, got %v", err)
+	}
+}
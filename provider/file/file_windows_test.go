@@ -0,0 +1,43 @@
+//go:build windows
+
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizePathWindows(t *testing.T) {
+	cases := map[string]string{
+		"C:/conf/app.json":         `C:\conf\app.json`,
+		"file:///C:/conf/app.json": `C:\conf\app.json`,
+		`\\server\share\app.json`:  `\\server\share\app.json`,
+		`C:\conf\..\conf\app.json`: `C:\conf\app.json`,
+	}
+	for in, want := range cases {
+		got, err := normalizePath(in, false)
+		if err != nil {
+			t.Fatalf("normalizePath(%q) error: %v", in, err)
+		}
+		if got != want {
+			t.Errorf("normalizePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestReadForwardSlashWindows(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(p, []byte("ok"), 0o644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	got, err := New(strings.ReplaceAll(p, `\`, "/")).Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if string(got) != "ok" {
+		t.Fatalf("got %q", string(got))
+	}
+}
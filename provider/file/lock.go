@@ -0,0 +1,40 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/go-sphere/confstore/internal/wait"
+)
+
+const (
+	lockRetryInterval = 10 * time.Millisecond
+	// lockStaleAfter is the age after which a lock file is assumed to have been
+	// left behind by a crashed process and is removed.
+	lockStaleAfter = 30 * time.Second
+)
+
+// lockFile acquires an exclusive lock by creating path with O_EXCL, retrying
+// until it succeeds or ctx is done. The returned func releases the lock.
+func lockFile(ctx context.Context, path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			_ = os.Remove(path)
+			continue
+		}
+		if err := wait.Sleep(ctx, lockRetryInterval); err != nil {
+			return nil, err
+		}
+	}
+}
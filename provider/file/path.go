@@ -0,0 +1,86 @@
+package file
+
+import (
+	"fmt"
+	"net/url"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+)
+
+// isDrivePath reports whether p starts with a Windows drive letter such as
+// "C:\", "c:/" or a bare "C:". url.Parse would otherwise read the drive letter
+// as a one-letter URL scheme.
+func isDrivePath(p string) bool {
+	if len(p) < 2 || p[1] != ':' {
+		return false
+	}
+	c := p[0]
+	if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+		return false
+	}
+	return len(p) == 2 || p[2] == '\\' || p[2] == '/'
+}
+
+// isUNCPath reports whether p is a Windows UNC path such as \\server\share\app.json.
+func isUNCPath(p string) bool {
+	return strings.HasPrefix(p, `\\`)
+}
+
+// hasFileScheme reports whether p is a "file:" URL.
+func hasFileScheme(p string) bool {
+	return len(p) >= 5 && strings.EqualFold(p[:5], "file:")
+}
+
+// fileURLPath extracts the local path from a parsed "file" URL. Only empty or
+// "localhost" hosts are accepted. A leading slash before a drive letter, as in
+// "file:///C:/app.json", is dropped.
+func fileURLPath(u *url.URL) (string, error) {
+	if u.Host != "" && !strings.EqualFold(u.Host, "localhost") {
+		return "", fmt.Errorf("file provider: non-local host %q in uri", u.Host)
+	}
+	p := u.Path
+	if u.Opaque != "" {
+		p = u.Opaque
+	}
+	if len(p) > 1 && p[0] == '/' && isDrivePath(p[1:]) {
+		p = p[1:]
+	}
+	return p, nil
+}
+
+// normalizePath converts a user-supplied path into the form expected by the
+// target filesystem. "file:" URLs are resolved to their path. For an fs.FS the
+// result is slash-separated, cleaned and unrooted as required by fs.ValidPath,
+// with backslashes treated as separators. For the OS filesystem the path is
+// cleaned and converted to the platform separator.
+func normalizePath(p string, forFS bool) (string, error) {
+	if hasFileScheme(p) {
+		u, err := url.Parse(p)
+		if err != nil {
+			return "", fmt.Errorf("file provider: parse uri %q: %w", p, err)
+		}
+		if p, err = fileURLPath(u); err != nil {
+			return "", err
+		}
+	}
+	if forFS {
+		p = pathpkg.Clean(strings.ReplaceAll(p, `\`, "/"))
+		p = strings.TrimLeft(p, "/")
+		if p == "" {
+			p = "."
+		}
+		return p, nil
+	}
+	return filepath.Clean(filepath.FromSlash(p)), nil
+}
+
+// pathSegment validates a context value substituted into a path template:
+// it must be a single path element, so a tenant ID cannot escape the
+// configured directory.
+func pathSegment(v string) (string, error) {
+	if v == "" || v == "." || v == ".." || strings.ContainsAny(v, `/\`+"\x00") {
+		return "", fmt.Errorf("%q is not a single path element", v)
+	}
+	return v, nil
+}
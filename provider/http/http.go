@@ -9,11 +9,25 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/go-sphere/confstore/provider"
 )
 
+func init() {
+	provider.Register("http", open)
+	provider.Register("https", open)
+}
+
+// open builds an HTTP provider for an "http" or "https" URI with default options.
+func open(_ context.Context, u *url.URL) (provider.Provider, error) {
+	return New(u.String()), nil
+}
+
 var (
 	// ErrBodyTooLarge indicates the HTTP response body exceeded the configured max size.
 	ErrBodyTooLarge = errors.New("http provider: body too large")
+	// ErrNotFound indicates a 404 or 410 response. It also matches fs.ErrNotExist.
+	ErrNotFound = provider.NewNotFound("http provider: not found")
 )
 
 // HTTP provides configuration bytes fetched from an HTTP(S) endpoint.
@@ -30,6 +44,10 @@ type options struct {
 	header  http.Header
 	// maxBodySize limits the response body size in bytes. 0 means unlimited.
 	maxBodySize int64
+	// watch configures Watch; see WithSSE and WithLongPoll.
+	watch          watchMode
+	pollInterval   time.Duration
+	reconnectDelay time.Duration
 }
 
 // Option configures optional behavior for the HTTP provider.
@@ -80,11 +98,34 @@ func WithHeaders(h http.Header) Option {
 // A non-positive value disables the limit.
 func WithMaxBodySize(n int64) Option { return func(o *options) { o.maxBodySize = n } }
 
+// WithSSE makes Watch subscribe to the URL as a Server-Sent Events stream.
+// Each event's data is treated as a complete configuration document.
+func WithSSE() Option { return func(o *options) { o.watch = watchSSE } }
+
+// WithLongPoll makes Watch long-poll the URL: each request carries the last
+// ETag in If-None-Match, the server holds it until the config changes and
+// answers 200 with the new document or 304 when nothing changed. interval is
+// the pause between consecutive requests; 0 re-polls immediately after a
+// change. A poll that brings no change waits at least the reconnect delay.
+// Clients with a Timeout (see WithTimeout) must allow for the server's hold
+// time.
+func WithLongPoll(interval time.Duration) Option {
+	return func(o *options) {
+		o.watch = watchLongPoll
+		o.pollInterval = interval
+	}
+}
+
+// WithReconnectDelay sets how long Watch waits before reconnecting after a
+// transient failure. Default: 3s. An SSE server can override it with "retry:".
+func WithReconnectDelay(d time.Duration) Option { return func(o *options) { o.reconnectDelay = d } }
+
 func newOptions(opts ...Option) *options {
 	o := &options{
 		// Default: no client timeout. Prefer caller-provided context.
-		timeout: 0,
-		method:  http.MethodGet,
+		timeout:        0,
+		method:         http.MethodGet,
+		reconnectDelay: 3 * time.Second,
 	}
 	for _, opt := range opts {
 		opt(o)
@@ -111,14 +152,9 @@ func (h *HTTP) Read(ctx context.Context) ([]byte, error) {
 	// Use caller-provided context for per-request cancellation/deadlines.
 	// If WithTimeout was specified without a custom client, client.Timeout
 	// is set in newHTTPOptions.
-	req, err := http.NewRequestWithContext(ctx, h.opts.method, h.url, nil)
+	req, err := h.newRequest(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("http provider: build request %s %s: %w", h.opts.method, h.url, err)
-	}
-	for k, vs := range h.opts.header {
-		for _, v := range vs {
-			req.Header.Add(k, v)
-		}
+		return nil, err
 	}
 	resp, err := h.opts.client.Do(req)
 	if err != nil {
@@ -127,8 +163,129 @@ func (h *HTTP) Read(ctx context.Context) ([]byte, error) {
 	defer func() { _ = resp.Body.Close() }()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		_, _ = io.Copy(io.Discard, resp.Body)
-		return nil, fmt.Errorf("http provider: %s %s unexpected status %s", h.opts.method, h.url, resp.Status)
+		return nil, h.statusError(resp)
 	}
+	return h.readBody(resp)
+}
+
+// Open implements provider.StreamProvider. It performs the request like Read
+// and returns the response body unread; WithMaxBodySize is enforced while
+// streaming, failing the read with ErrBodyTooLarge once the limit is passed.
+func (h *HTTP) Open(ctx context.Context) (io.ReadCloser, error) {
+	req, err := h.newRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.opts.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http provider: do request %s %s: %w", h.opts.method, h.url, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		return nil, h.statusError(resp)
+	}
+	if h.opts.maxBodySize > 0 && resp.ContentLength > h.opts.maxBodySize {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: content-length %d exceeds limit %d", ErrBodyTooLarge, resp.ContentLength, h.opts.maxBodySize)
+	}
+	if h.opts.maxBodySize <= 0 {
+		return resp.Body, nil
+	}
+	return &limitedBody{ReadCloser: resp.Body, remaining: h.opts.maxBodySize}, nil
+}
+
+// limitedBody fails with ErrBodyTooLarge once more than the limit is read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrBodyTooLarge
+	}
+	return n, err
+}
+
+// expandURL fills "{key}" placeholders in raw from provider.Values(ctx),
+// escaping each value for the URL component it lands in: path-escaped in the
+// path and query-escaped in the query. Values are rejected in the scheme,
+// host and fragment, where they could redirect the request.
+func expandURL(ctx context.Context, raw string) (string, error) {
+	rest, fragment, hasFragment := strings.Cut(raw, "#")
+	rest, query, hasQuery := strings.Cut(rest, "?")
+	authority := ""
+	if i := strings.Index(rest, "://"); i >= 0 {
+		end := strings.IndexByte(rest[i+3:], '/')
+		if end < 0 {
+			end = len(rest) - i - 3
+		}
+		authority, rest = rest[:i+3+end], rest[i+3+end:]
+	}
+	reject := func(string) (string, error) { return "", errors.New("placeholder outside path and query") }
+	pathEscape := func(v string) (string, error) { return url.PathEscape(v), nil }
+	queryEscape := func(v string) (string, error) { return url.QueryEscape(v), nil }
+
+	var b strings.Builder
+	for _, part := range []struct {
+		sep, s string
+		escape func(string) (string, error)
+		ok     bool
+	}{
+		{"", authority, reject, true},
+		{"", rest, pathEscape, true},
+		{"?", query, queryEscape, hasQuery},
+		{"#", fragment, reject, hasFragment},
+	} {
+		if !part.ok {
+			continue
+		}
+		expanded, err := provider.ExpandValues(ctx, part.s, part.escape)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(part.sep + expanded)
+	}
+	return b.String(), nil
+}
+
+// newRequest builds a request for the configured method, URL and headers.
+// "{key}" placeholders in the URL are filled from provider.Values(ctx).
+func (h *HTTP) newRequest(ctx context.Context) (*http.Request, error) {
+	u, err := expandURL(ctx, h.url)
+	if err != nil {
+		return nil, fmt.Errorf("http provider: build request %s %s: %w", h.opts.method, h.url, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, h.opts.method, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("http provider: build request %s %s: %w", h.opts.method, h.url, err)
+	}
+	for k, vs := range h.opts.header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	return req, nil
+}
+
+func (h *HTTP) statusError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return fmt.Errorf("%w: %s %s status %s", ErrNotFound, h.opts.method, h.url, resp.Status)
+	}
+	return fmt.Errorf("http provider: %s %s unexpected status %s", h.opts.method, h.url, resp.Status)
+}
+
+// readBody reads the response body, enforcing the configured max body size.
+func (h *HTTP) readBody(resp *http.Response) ([]byte, error) {
 	var reader io.Reader = resp.Body
 	// Fast-fail when Content-Length is known to exceed the limit.
 	if h.opts.maxBodySize > 0 && resp.ContentLength > h.opts.maxBodySize {
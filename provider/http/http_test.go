@@ -6,9 +6,14 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-sphere/confstore/provider"
+	"github.com/go-sphere/confstore/provider/providertest"
 )
 
 type rtFunc func(*http.Request) (*http.Response, error)
@@ -123,3 +128,101 @@ func TestHTTPContextTimeout(t *testing.T) {
 		t.Fatalf("expected DeadlineExceeded, got %v", err)
 	}
 }
+
+func TestHTTPRegisteredSchemes(t *testing.T) {
+	p, err := provider.Open(context.Background(), "https://example/config.json")
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	h, ok := p.(*HTTP)
+	if !ok {
+		t.Fatalf("expected *HTTP, got %T", p)
+	}
+	if h.url != "https://example/config.json" {
+		t.Fatalf("got url %q", h.url)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	serve := func(t *testing.T, data []byte) string {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(data)
+		}))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+	providertest.TestProvider(t, providertest.Harness{
+		New: func(t *testing.T, data []byte) provider.Provider { return New(serve(t, data)) },
+		Missing: func(t *testing.T) provider.Provider {
+			srv := httptest.NewServer(http.NotFoundHandler())
+			t.Cleanup(srv.Close)
+			return New(srv.URL)
+		},
+		Limited: func(t *testing.T, data []byte, limit int64) provider.Provider {
+			return New(serve(t, data), WithMaxBodySize(limit))
+		},
+		LimitErr:           ErrBodyTooLarge,
+		HonorsCancellation: true,
+	})
+}
+
+func TestHTTPURLTemplate(t *testing.T) {
+	var gotPath string
+	c := &http.Client{Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+		gotPath = r.URL.EscapedPath()
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	})}
+	ctx := provider.WithValues(context.Background(), map[string]string{"tenant": "a/b"})
+	if _, err := New("http://example/configs/{tenant}.json", WithClient(c)).Read(ctx); err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if gotPath != "/configs/a%2Fb.json" {
+		t.Fatalf("unexpected path %q", gotPath)
+	}
+}
+
+func TestHTTPURLTemplateComponents(t *testing.T) {
+	var got *url.URL
+	c := &http.Client{Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+		got = r.URL
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	})}
+	ctx := provider.WithValues(context.Background(), map[string]string{"tenant": "acme&admin=1", "host": "evil.example"})
+	if _, err := New("http://example/configs?tenant={tenant}&v=1", WithClient(c)).Read(ctx); err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if q := got.Query(); q.Get("tenant") != "acme&admin=1" || q.Has("admin") || q.Get("v") != "1" {
+		t.Fatalf("query value not escaped: %q", got.RawQuery)
+	}
+	for _, raw := range []string{"http://{host}/configs.json", "http://example/configs.json#{tenant}"} {
+		if _, err := New(raw, WithClient(c)).Read(ctx); !errors.Is(err, provider.ErrInvalidValue) {
+			t.Fatalf("%s: expected ErrInvalidValue, got %v", raw, err)
+		}
+	}
+}
+
+func TestHTTPOpenEnforcesLimit(t *testing.T) {
+	body := strings.Repeat("a", 100)
+	c := &http.Client{Transport: rtFunc(func(r *http.Request) (*http.Response, error) {
+		// Unknown length, so the limit must be enforced while streaming.
+		return &http.Response{StatusCode: 200, ContentLength: -1, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})}
+	rc, err := New("http://example/big", WithClient(c), WithMaxBodySize(10)).Open(context.Background())
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	defer func() { _ = rc.Close() }()
+	data, err := io.ReadAll(rc)
+	if !errors.Is(err, ErrBodyTooLarge) || len(data) != 10 {
+		t.Fatalf("ReadAll = %d bytes, %v", len(data), err)
+	}
+
+	rc, err = New("http://example/big", WithClient(c)).Open(context.Background())
+	if err != nil {
+		t.Fatalf("Open error: %v", err)
+	}
+	if data, err = io.ReadAll(rc); err != nil || string(data) != body {
+		t.Fatalf("ReadAll = %d bytes, %v", len(data), err)
+	}
+	_ = rc.Close()
+}
@@ -0,0 +1,209 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sphere/confstore/internal/wait"
+	"github.com/go-sphere/confstore/provider"
+)
+
+type watchMode int
+
+const (
+	watchNone watchMode = iota
+	watchSSE
+	watchLongPoll
+)
+
+// permanentError marks failures that reconnecting cannot fix.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Watch implements provider.Watcher. It requires WithSSE or WithLongPoll and
+// returns provider.ErrWatchUnsupported otherwise. Connection failures and 5xx
+// or 429 responses are retried after the reconnect delay; other unexpected
+// statuses end the watch with an error. onChange is only called when the
+// delivered document differs from the previous one. A request that brings no
+// change is followed by at least the reconnect delay, so a server answering
+// at once (or an SSE "retry: 0") cannot make Watch spin.
+func (h *HTTP) Watch(ctx context.Context, onChange func(data []byte)) error {
+	var last []byte
+	delivered, changed := false, false
+	deliver := func(data []byte) {
+		if delivered && bytes.Equal(data, last) {
+			return
+		}
+		last, delivered, changed = data, true, true
+		onChange(data)
+	}
+	var (
+		step func(ctx context.Context, deliver func([]byte)) error
+		sse  *sseState
+	)
+	switch h.opts.watch {
+	case watchSSE:
+		sse = &sseState{retry: h.opts.reconnectDelay}
+		step = func(ctx context.Context, deliver func([]byte)) error { return h.sseOnce(ctx, sse, deliver) }
+	case watchLongPoll:
+		etag := ""
+		step = func(ctx context.Context, deliver func([]byte)) error { return h.longPollOnce(ctx, &etag, deliver) }
+	default:
+		return fmt.Errorf("%w: configure http.WithSSE or http.WithLongPoll", provider.ErrWatchUnsupported)
+	}
+	for {
+		changed = false
+		err := step(ctx, deliver)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var perm permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		delay := h.opts.pollInterval
+		switch {
+		case sse != nil:
+			delay = sse.retry
+		case err != nil:
+			delay = h.opts.reconnectDelay
+		}
+		if err == nil && !changed {
+			delay = max(delay, h.opts.reconnectDelay)
+		}
+		if err = wait.Sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// checkStatus classifies an unexpected response status as transient or permanent.
+func (h *HTTP) checkStatus(resp *http.Response) error {
+	err := h.statusError(resp)
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return err
+	}
+	return permanentError{err}
+}
+
+func (h *HTTP) longPollOnce(ctx context.Context, etag *string, deliver func([]byte)) error {
+	req, err := h.newRequest(ctx)
+	if err != nil {
+		return permanentError{err}
+	}
+	if *etag != "" {
+		req.Header.Set("If-None-Match", *etag)
+	}
+	resp, err := h.opts.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http provider: do request %s %s: %w", h.opts.method, h.url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return h.checkStatus(resp)
+	}
+	data, err := h.readBody(resp)
+	if err != nil {
+		return err
+	}
+	*etag = resp.Header.Get("ETag")
+	deliver(data)
+	return nil
+}
+
+// sseState survives reconnects so the stream can resume from the last event.
+type sseState struct {
+	lastEventID string
+	retry       time.Duration
+}
+
+func (h *HTTP) sseOnce(ctx context.Context, s *sseState, deliver func([]byte)) error {
+	req, err := h.newRequest(ctx)
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if s.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", s.lastEventID)
+	}
+	resp, err := h.opts.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http provider: do request %s %s: %w", h.opts.method, h.url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return h.checkStatus(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	maxLine := 1 << 20
+	if h.opts.maxBodySize > 0 {
+		maxLine = int(h.opts.maxBodySize) + len("data: ") + 1
+	}
+	scanner.Buffer(make([]byte, 0, 4096), maxLine)
+	var (
+		data      bytes.Buffer
+		eventType string
+	)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			// Blank line dispatches the event.
+			if data.Len() > 0 && (eventType == "" || eventType == "message") {
+				payload := bytes.TrimSuffix(data.Bytes(), []byte{'\n'})
+				deliver(append([]byte(nil), payload...))
+			}
+			data.Reset()
+			eventType = ""
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment / keep-alive
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			if h.opts.maxBodySize > 0 && int64(data.Len()+len(value)) > h.opts.maxBodySize {
+				return fmt.Errorf("%w: event data exceeds limit %d", ErrBodyTooLarge, h.opts.maxBodySize)
+			}
+			data.WriteString(value)
+			data.WriteByte('\n')
+		case "event":
+			eventType = value
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				s.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("%w: event line exceeds limit", ErrBodyTooLarge)
+		}
+		return fmt.Errorf("http provider: read stream %s %s: %w", h.opts.method, h.url, err)
+	}
+	// The server closed the stream; reconnect.
+	return nil
+}
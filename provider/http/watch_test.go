@@ -0,0 +1,135 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/confstore/provider"
+)
+
+func collect(t *testing.T, p *HTTP, want int) []string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []string
+	err := p.Watch(ctx, func(data []byte) {
+		got = append(got, string(data))
+		if len(got) == want {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Watch returned %v, got %q", err, got)
+	}
+	return got
+}
+
+func TestWatchSSE(t *testing.T) {
+	var mu sync.Mutex
+	var lastIDs []string
+	conn := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conn++
+		n := conn
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		if n == 1 {
+			fmt.Fprint(w, "retry: 1\n: keep-alive\n\nid: 1\ndata: {\"v\":1}\n\nevent: ping\ndata: x\n\nid: 2\ndata: {\"v\":\ndata: 2}\n\n")
+			return // server closes; client reconnects
+		}
+		fmt.Fprint(w, "id: 2\ndata: {\"v\":\ndata: 2}\n\nid: 3\ndata: {\"v\":3}\n\n")
+	}))
+	defer srv.Close()
+
+	got := collect(t, New(srv.URL, WithSSE()), 3)
+	want := []string{`{"v":1}`, "{\"v\":\n2}", `{"v":3}`}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lastIDs) < 2 || lastIDs[0] != "" || lastIDs[1] != "2" {
+		t.Fatalf("unexpected Last-Event-ID headers: %q", lastIDs)
+	}
+}
+
+func TestWatchLongPoll(t *testing.T) {
+	versions := []string{"v1", "v1", "v2", "v2"}
+	var mu sync.Mutex
+	i := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if i >= len(versions) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		v := versions[i]
+		i++
+		if r.Header.Get("If-None-Match") == v {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if i == 3 {
+			// Transient failure on the first v2 poll is retried.
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("ETag", v)
+		fmt.Fprint(w, "config-"+v)
+	}))
+	defer srv.Close()
+
+	got := collect(t, New(srv.URL, WithLongPoll(0), WithReconnectDelay(time.Millisecond)), 2)
+	if fmt.Sprint(got) != "[config-v1 config-v2]" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestWatchPermanentError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	err := New(srv.URL, WithLongPoll(0)).Watch(context.Background(), func([]byte) {})
+	if err == nil || errors.Is(err, context.Canceled) {
+		t.Fatalf("expected permanent error, got %v", err)
+	}
+}
+
+func TestWatchUnsupported(t *testing.T) {
+	var w provider.Watcher = New("http://example")
+	if err := w.Watch(context.Background(), func([]byte) {}); !errors.Is(err, provider.ErrWatchUnsupported) {
+		t.Fatalf("expected ErrWatchUnsupported, got %v", err)
+	}
+}
+
+func TestWatchLongPollBacksOffWhenUnchanged(t *testing.T) {
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls.Add(1)
+		w.Header().Set("ETag", "v1")
+		if r.Header.Get("If-None-Match") == "v1" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, "config-v1")
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_ = New(srv.URL, WithLongPoll(0), WithReconnectDelay(50*time.Millisecond)).Watch(ctx, func([]byte) {})
+	if n := polls.Load(); n > 6 {
+		t.Fatalf("expected unchanged polls to back off, got %d in 200ms", n)
+	}
+}
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// Memory is a mutable in-memory provider. It is safe for concurrent use and
+// implements Writer and Watcher, so tests and admin APIs can drive config
+// changes through the same pipeline as production sources.
+type Memory struct {
+	mu      sync.Mutex
+	data    []byte
+	version uint64        // incremented on every change
+	changed chan struct{} // closed and replaced on every change
+}
+
+// NewMemory creates a Memory provider holding a copy of initial.
+func NewMemory(initial []byte) *Memory {
+	return &Memory{data: bytes.Clone(initial), changed: make(chan struct{})}
+}
+
+// Read returns a copy of the current configuration.
+func (m *Memory) Read(_ context.Context) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return bytes.Clone(m.data), nil
+}
+
+// Set replaces the configuration with a copy of data. Watchers are notified
+// if the content changed.
+func (m *Memory) Set(data []byte) {
+	m.Update(func([]byte) []byte { return data })
+}
+
+// Update atomically replaces the configuration with the result of f, which
+// receives a copy of the current configuration. Watchers are notified if the
+// content changed.
+//
+// f runs without holding the lock, so it may call back into m. If the
+// configuration changes while f runs, f is called again with the new
+// content, so it must be safe to call repeatedly.
+func (m *Memory) Update(f func(current []byte) []byte) {
+	for {
+		m.mu.Lock()
+		current, version := bytes.Clone(m.data), m.version
+		m.mu.Unlock()
+		next := bytes.Clone(f(current))
+
+		m.mu.Lock()
+		if m.version != version {
+			m.mu.Unlock()
+			continue
+		}
+		if !bytes.Equal(next, m.data) {
+			m.data = next
+			m.version++
+			close(m.changed)
+			m.changed = make(chan struct{})
+		}
+		m.mu.Unlock()
+		return
+	}
+}
+
+// Write implements Writer; it is equivalent to Set.
+func (m *Memory) Write(_ context.Context, data []byte) error {
+	m.Set(data)
+	return nil
+}
+
+// Watch implements Watcher. The current configuration is delivered first,
+// then every change. Changes made while onChange is running are coalesced, so
+// a slow consumer observes the latest configuration rather than every
+// intermediate one.
+func (m *Memory) Watch(ctx context.Context, onChange func(data []byte)) error {
+	var last []byte
+	delivered := false
+	for {
+		m.mu.Lock()
+		data, changed := bytes.Clone(m.data), m.changed
+		m.mu.Unlock()
+		if !delivered || !bytes.Equal(data, last) {
+			last, delivered = data, true
+			onChange(bytes.Clone(data))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
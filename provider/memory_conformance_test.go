@@ -0,0 +1,15 @@
+package provider_test
+
+import (
+	"testing"
+
+	"github.com/go-sphere/confstore/provider"
+	"github.com/go-sphere/confstore/provider/providertest"
+)
+
+// The conformance suite imports provider, so it runs from the external test package.
+func TestMemoryConformance(t *testing.T) {
+	providertest.TestProvider(t, providertest.Harness{
+		New: func(t *testing.T, data []byte) provider.Provider { return provider.NewMemory(data) },
+	})
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemory_ReadSetUpdate(t *testing.T) {
+	initial := []byte("a")
+	m := NewMemory(initial)
+	initial[0] = 'x'
+	if got, _ := m.Read(context.Background()); string(got) != "a" {
+		t.Fatalf("expected copy of initial, got %q", got)
+	}
+	m.Set([]byte("b"))
+	m.Update(func(current []byte) []byte { return append(current, 'c') })
+	if got, _ := m.Read(context.Background()); string(got) != "bc" {
+		t.Fatalf("unexpected content %q", got)
+	}
+}
+
+func TestMemory_ConcurrentUpdates(t *testing.T) {
+	m := NewMemory(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Update(func(current []byte) []byte { return append(current, '.') })
+		}()
+	}
+	wg.Wait()
+	if got, _ := m.Read(context.Background()); len(got) != 50 {
+		t.Fatalf("lost updates: %d", len(got))
+	}
+}
+
+func TestMemory_UpdateMayCallBack(t *testing.T) {
+	m := NewMemory([]byte("a"))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Update(func(current []byte) []byte {
+			peek, _ := m.Read(context.Background())
+			return append(peek, 'b')
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Update deadlocked when f read from the provider")
+	}
+	if got, _ := m.Read(context.Background()); string(got) != "ab" {
+		t.Fatalf("unexpected content %q", got)
+	}
+}
+
+func TestMemory_Watch(t *testing.T) {
+	m := NewMemory([]byte("v1"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := make(chan string, 8)
+	done := make(chan error, 1)
+	go func() { done <- m.Watch(ctx, func(data []byte) { got <- string(data) }) }()
+
+	if v := <-got; v != "v1" {
+		t.Fatalf("expected initial v1, got %q", v)
+	}
+	m.Set([]byte("v1")) // unchanged: no event
+	m.Set([]byte("v2"))
+	select {
+	case v := <-got:
+		if v != "v2" {
+			t.Fatalf("expected v2, got %q", v)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for change")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
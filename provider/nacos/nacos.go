@@ -0,0 +1,259 @@
+// Package nacos provides a configuration provider for the Nacos config center.
+//
+// A config is addressed by namespace (tenant), group and data ID. Read fetches
+// it through the Nacos open API and Watch subscribes to changes with the
+// native long-polling listener, surfacing them through provider.Watcher.
+package nacos
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sphere/confstore/internal/wait"
+	"github.com/go-sphere/confstore/provider"
+)
+
+var (
+	// ErrNotFound indicates that the requested config does not exist.
+	ErrNotFound = provider.NewNotFound("nacos provider: config not found")
+	// ErrBodyTooLarge indicates a response body exceeding the configured max size.
+	ErrBodyTooLarge = errors.New("nacos provider: body too large")
+)
+
+// DefaultMaxBodySize is the response size limit used unless WithMaxBodySize is given.
+const DefaultMaxBodySize = 10 << 20
+
+// Nacos reads a single config from a Nacos server.
+type Nacos struct {
+	server string
+	dataID string
+	group  string
+	opts   *options
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+type options struct {
+	namespace       string
+	client          *http.Client
+	username        string
+	password        string
+	longPollTimeout time.Duration
+	reconnectDelay  time.Duration
+	maxBodySize     int64
+}
+
+// Option configures optional behavior for the Nacos provider.
+type Option func(*options)
+
+// WithNamespace sets the namespace (tenant) ID. Default: the public namespace.
+func WithNamespace(ns string) Option { return func(o *options) { o.namespace = ns } }
+
+// WithClient sets a custom HTTP client.
+func WithClient(c *http.Client) Option { return func(o *options) { o.client = c } }
+
+// WithAuth enables Nacos authentication. The provider logs in with the given
+// credentials and refreshes the access token before it expires.
+func WithAuth(username, password string) Option {
+	return func(o *options) { o.username, o.password = username, password }
+}
+
+// WithLongPollTimeout sets how long the server may hold a listener request. Default: 30s.
+func WithLongPollTimeout(d time.Duration) Option { return func(o *options) { o.longPollTimeout = d } }
+
+// WithReconnectDelay sets the pause before retrying after a failed request in Watch. Default: 3s.
+func WithReconnectDelay(d time.Duration) Option { return func(o *options) { o.reconnectDelay = d } }
+
+// WithMaxBodySize limits response bodies to n bytes; larger responses fail
+// with ErrBodyTooLarge. A non-positive value disables the limit. Default:
+// DefaultMaxBodySize.
+func WithMaxBodySize(n int64) Option { return func(o *options) { o.maxBodySize = n } }
+
+// New creates a Nacos provider. server is the base URL of the Nacos server,
+// e.g. "http://127.0.0.1:8848"; group defaults to "DEFAULT_GROUP" when empty.
+func New(server, dataID, group string, opts ...Option) *Nacos {
+	o := &options{longPollTimeout: 30 * time.Second, reconnectDelay: 3 * time.Second, maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.client == nil {
+		o.client = &http.Client{}
+	}
+	if group == "" {
+		group = "DEFAULT_GROUP"
+	}
+	return &Nacos{server: strings.TrimRight(server, "/"), dataID: dataID, group: group, opts: o}
+}
+
+// Read implements provider.Provider.
+func (n *Nacos) Read(ctx context.Context) ([]byte, error) {
+	q := url.Values{"dataId": {n.dataID}, "group": {n.group}}
+	if n.opts.namespace != "" {
+		q.Set("tenant", n.opts.namespace)
+	}
+	if err := n.authorize(ctx, q); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.server+"/nacos/v1/cs/configs?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("nacos provider: build request: %w", err)
+	}
+	data, status, err := n.do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case status == http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s/%s", ErrNotFound, n.group, n.dataID)
+	case status < 200 || status >= 300:
+		return nil, fmt.Errorf("nacos provider: get config %s/%s unexpected status %d", n.group, n.dataID, status)
+	}
+	return data, nil
+}
+
+// Watch implements provider.Watcher using the Nacos long-polling listener.
+// The current config is delivered first; afterwards onChange is called each
+// time the server reports a change. Failed requests are retried after the
+// reconnect delay.
+func (n *Nacos) Watch(ctx context.Context, onChange func(data []byte)) error {
+	var last []byte
+	delivered, changed := false, true
+	for {
+		var err error
+		if changed {
+			var data []byte
+			if data, err = n.Read(ctx); err == nil && (!delivered || !bytes.Equal(data, last)) {
+				last, delivered = data, true
+				onChange(data)
+			}
+		}
+		if err == nil {
+			changed, err = n.listen(ctx, md5Hex(last))
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			changed = true
+			if err = wait.Sleep(ctx, n.opts.reconnectDelay); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// listen blocks until the server reports a change or the long-poll times out.
+func (n *Nacos) listen(ctx context.Context, md5sum string) (bool, error) {
+	key := n.dataID + "\x02" + n.group + "\x02" + md5sum
+	if n.opts.namespace != "" {
+		key += "\x02" + n.opts.namespace
+	}
+	form := url.Values{"Listening-Configs": {key + "\x01"}}
+	q := url.Values{}
+	if err := n.authorize(ctx, q); err != nil {
+		return false, err
+	}
+	u := n.server + "/nacos/v1/cs/configs/listener"
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, fmt.Errorf("nacos provider: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Long-Pulling-Timeout", strconv.FormatInt(n.opts.longPollTimeout.Milliseconds(), 10))
+	data, status, err := n.do(req)
+	if err != nil {
+		return false, err
+	}
+	if status < 200 || status >= 300 {
+		return false, fmt.Errorf("nacos provider: listen %s/%s unexpected status %d", n.group, n.dataID, status)
+	}
+	return len(bytes.TrimSpace(data)) > 0, nil
+}
+
+// authorize adds the access token to q when authentication is configured.
+func (n *Nacos) authorize(ctx context.Context, q url.Values) error {
+	if n.opts.username == "" {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.token == "" || time.Now().After(n.tokenExpiry) {
+		if err := n.login(ctx); err != nil {
+			return err
+		}
+	}
+	q.Set("accessToken", n.token)
+	return nil
+}
+
+func (n *Nacos) login(ctx context.Context) error {
+	form := url.Values{"username": {n.opts.username}, "password": {n.opts.password}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.server+"/nacos/v1/auth/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("nacos provider: build login request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	data, status, err := n.do(req)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("nacos provider: login unexpected status %d", status)
+	}
+	var resp struct {
+		AccessToken string `json:"accessToken"`
+		TokenTTL    int64  `json:"tokenTtl"`
+	}
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("nacos provider: login: decode response: %w", err)
+	}
+	if resp.AccessToken == "" {
+		return fmt.Errorf("nacos provider: login: response has no access token")
+	}
+	n.token = resp.AccessToken
+	// Refresh at 90% of the TTL to avoid using a token right as it expires.
+	n.tokenExpiry = time.Now().Add(time.Duration(resp.TokenTTL) * time.Second * 9 / 10)
+	return nil
+}
+
+func (n *Nacos) do(req *http.Request) ([]byte, int, error) {
+	resp, err := n.opts.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("nacos provider: do request %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var body io.Reader = resp.Body
+	if n.opts.maxBodySize > 0 {
+		body = io.LimitReader(resp.Body, n.opts.maxBodySize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("nacos provider: read body %s %s: %w", req.Method, req.URL.Path, err)
+	}
+	if n.opts.maxBodySize > 0 && int64(len(data)) > n.opts.maxBodySize {
+		return nil, 0, fmt.Errorf("%w: %s %s exceeds limit %d", ErrBodyTooLarge, req.Method, req.URL.Path, n.opts.maxBodySize)
+	}
+	return data, resp.StatusCode, nil
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
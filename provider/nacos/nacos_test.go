@@ -0,0 +1,177 @@
+package nacos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sphere/confstore/provider"
+	"github.com/go-sphere/confstore/provider/providertest"
+)
+
+type fakeServer struct {
+	mu      sync.Mutex
+	content map[string]string // group/dataId -> content
+	logins  int
+}
+
+func (f *fakeServer) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /nacos/v1/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("username") != "nacos" || r.FormValue("password") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		f.mu.Lock()
+		f.logins++
+		f.mu.Unlock()
+		_, _ = w.Write([]byte(`{"accessToken":"tok","tokenTtl":18000}`))
+	})
+	mux.HandleFunc("GET /nacos/v1/cs/configs", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		authed := f.logins == 0 || r.URL.Query().Get("accessToken") == "tok"
+		c, ok := f.content[r.URL.Query().Get("group")+"/"+r.URL.Query().Get("dataId")]
+		f.mu.Unlock()
+		if !authed {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(c))
+	})
+	mux.HandleFunc("POST /nacos/v1/cs/configs/listener", func(w http.ResponseWriter, r *http.Request) {
+		key := r.FormValue("Listening-Configs")
+		parts := strings.Split(strings.TrimSuffix(key, "\x01"), "\x02")
+		if len(parts) < 3 {
+			t.Errorf("malformed listening key %q", key)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		current := md5Hex([]byte(f.content[parts[1]+"/"+parts[0]]))
+		f.mu.Unlock()
+		if current != parts[2] {
+			_, _ = w.Write([]byte(parts[0] + "%02" + parts[1] + "%01"))
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+	return mux
+}
+
+func TestRead(t *testing.T) {
+	f := &fakeServer{content: map[string]string{"DEFAULT_GROUP/app.json": `{"a":1}`}}
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	got, err := New(srv.URL, "app.json", "").Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if string(got) != `{"a":1}` {
+		t.Fatalf("unexpected content: %s", got)
+	}
+
+	_, err = New(srv.URL, "missing", "").Read(context.Background())
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestReadMaxBodySize(t *testing.T) {
+	f := &fakeServer{content: map[string]string{"DEFAULT_GROUP/app.json": `{"a":1}`}}
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	_, err := New(srv.URL, "app.json", "", WithMaxBodySize(4)).Read(context.Background())
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+	if _, err := New(srv.URL, "app.json", "", WithMaxBodySize(7)).Read(context.Background()); err != nil {
+		t.Fatalf("Read at exact limit error: %v", err)
+	}
+}
+
+func TestReadWithAuth(t *testing.T) {
+	f := &fakeServer{content: map[string]string{"g/app": `x`}}
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	n := New(srv.URL, "app", "g", WithAuth("nacos", "secret"))
+	for i := 0; i < 2; i++ {
+		if _, err := n.Read(context.Background()); err != nil {
+			t.Fatalf("Read error: %v", err)
+		}
+	}
+	if f.logins != 1 {
+		t.Fatalf("expected token to be reused, got %d logins", f.logins)
+	}
+
+	if _, err := New(srv.URL, "app", "g", WithAuth("nacos", "wrong")).Read(context.Background()); err == nil {
+		t.Fatalf("expected login failure")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	f := &fakeServer{content: map[string]string{"DEFAULT_GROUP/app": `v1`}}
+	srv := httptest.NewServer(f.handler(t))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got := make(chan string, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- New(srv.URL, "app", "", WithReconnectDelay(10*time.Millisecond)).Watch(ctx, func(data []byte) { got <- string(data) })
+	}()
+
+	if v := <-got; v != "v1" {
+		t.Fatalf("expected initial v1, got %q", v)
+	}
+	f.mu.Lock()
+	f.content["DEFAULT_GROUP/app"] = "v2"
+	f.mu.Unlock()
+	select {
+	case v := <-got:
+		if v != "v2" {
+			t.Fatalf("expected v2, got %q", v)
+		}
+	case <-ctx.Done():
+		t.Fatalf("timed out waiting for change")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	serve := func(t *testing.T, data []byte) string {
+		f := &fakeServer{content: map[string]string{"DEFAULT_GROUP/app": string(data)}}
+		srv := httptest.NewServer(f.handler(t))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+	providertest.TestProvider(t, providertest.Harness{
+		New: func(t *testing.T, data []byte) provider.Provider { return New(serve(t, data), "app", "") },
+		Missing: func(t *testing.T) provider.Provider {
+			return New(serve(t, nil), "missing", "")
+		},
+		Limited: func(t *testing.T, data []byte, limit int64) provider.Provider {
+			return New(serve(t, data), "app", "", WithMaxBodySize(limit))
+		},
+		LimitErr:           ErrBodyTooLarge,
+		HonorsCancellation: true,
+	})
+}
@@ -0,0 +1,17 @@
+package provider
+
+import "io/fs"
+
+// NewNotFound returns a sentinel error for providers to report that their
+// configuration source does not exist. Besides matching itself, the error
+// matches fs.ErrNotExist under errors.Is, so callers can classify missing
+// sources uniformly regardless of the provider:
+//
+//	var ErrNotFound = provider.NewNotFound("nacos provider: config not found")
+func NewNotFound(msg string) error { return &notFoundError{msg: msg} }
+
+type notFoundError struct{ msg string }
+
+func (e *notFoundError) Error() string { return e.msg }
+
+func (e *notFoundError) Is(target error) bool { return target == fs.ErrNotExist }
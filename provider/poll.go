@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Poll adapts any Provider into a Watcher by calling Read periodically and
+// reporting documents that differ from the previous one.
+//
+// With WithAdaptiveInterval the interval adapts AIMD-style within bounds: it
+// is halved whenever a change is observed, so follow-up changes propagate
+// quickly, and grows by the lower bound after every quiet poll, reducing load
+// on the backend while nothing changes.
+type Poll struct {
+	provider Provider
+	opts     *pollOptions
+	current  atomic.Int64 // current interval in nanoseconds
+}
+
+type pollOptions struct {
+	interval time.Duration
+	min, max time.Duration
+	adaptive bool
+	onError  func(error)
+}
+
+// PollOption configures a Poll adapter.
+type PollOption func(*pollOptions)
+
+// WithAdaptiveInterval lets the poll interval adapt between min and max.
+func WithAdaptiveInterval(min, max time.Duration) PollOption {
+	return func(o *pollOptions) {
+		o.adaptive = true
+		o.min, o.max = min, max
+	}
+}
+
+// WithPollErrorHandler sets a callback for Read errors during Watch.
+// Errors do not stop the watch; polling continues at the current interval.
+func WithPollErrorHandler(f func(error)) PollOption {
+	return func(o *pollOptions) { o.onError = f }
+}
+
+// NewPoll wraps p so that Watch polls it every interval. A non-positive
+// interval defaults to one minute rather than polling in a busy loop.
+func NewPoll(p Provider, interval time.Duration, opts ...PollOption) *Poll {
+	o := &pollOptions{interval: interval}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.interval <= 0 {
+		o.interval = time.Minute
+	}
+	if o.adaptive {
+		if o.min <= 0 {
+			o.min = time.Millisecond
+		}
+		if o.max < o.min {
+			o.max = o.min
+		}
+		o.interval = min(max(o.interval, o.min), o.max)
+	}
+	poll := &Poll{provider: p, opts: o}
+	poll.current.Store(int64(o.interval))
+	return poll
+}
+
+// Read implements Provider by reading from the wrapped provider.
+func (p *Poll) Read(ctx context.Context) ([]byte, error) {
+	return p.provider.Read(ctx)
+}
+
+// Interval returns the interval before the next poll.
+func (p *Poll) Interval() time.Duration {
+	return time.Duration(p.current.Load())
+}
+
+// Watch implements Watcher. The first successful Read is always delivered.
+func (p *Poll) Watch(ctx context.Context, onChange func(data []byte)) error {
+	var last []byte
+	delivered := false
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		data, err := p.provider.Read(ctx)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if p.opts.onError != nil {
+				p.opts.onError(err)
+			}
+		case !delivered || !bytes.Equal(data, last):
+			changed := delivered
+			last, delivered = data, true
+			onChange(data)
+			if changed {
+				p.adapt(true)
+			}
+		default:
+			p.adapt(false)
+		}
+		timer.Reset(p.Interval())
+	}
+}
+
+func (p *Poll) adapt(changed bool) {
+	if !p.opts.adaptive {
+		return
+	}
+	p.current.Store(int64(nextInterval(p.Interval(), changed, p.opts.min, p.opts.max)))
+}
+
+// nextInterval applies multiplicative decrease after a change and additive
+// increase (by lo) after a quiet poll, clamped to [lo, hi].
+func nextInterval(cur time.Duration, changed bool, lo, hi time.Duration) time.Duration {
+	if changed {
+		cur /= 2
+	} else {
+		cur += lo
+	}
+	return min(max(cur, lo), hi)
+}
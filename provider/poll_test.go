@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNextInterval(t *testing.T) {
+	lo, hi := time.Second, 10*time.Second
+	cases := []struct {
+		cur     time.Duration
+		changed bool
+		want    time.Duration
+	}{
+		{8 * time.Second, true, 4 * time.Second},
+		{time.Second, true, time.Second},
+		{4 * time.Second, false, 5 * time.Second},
+		{10 * time.Second, false, 10 * time.Second},
+	}
+	for _, c := range cases {
+		if got := nextInterval(c.cur, c.changed, lo, hi); got != c.want {
+			t.Errorf("nextInterval(%v, %v) = %v, want %v", c.cur, c.changed, got, c.want)
+		}
+	}
+}
+
+func TestNewPoll_DefaultsNonPositiveInterval(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		if got := NewPoll(NewMemory(nil), d).Interval(); got != time.Minute {
+			t.Errorf("NewPoll(%v).Interval() = %v, want %v", d, got, time.Minute)
+		}
+	}
+}
+
+func TestPoll_WatchDeliversChanges(t *testing.T) {
+	var mu sync.Mutex
+	reads := 0
+	seq := []string{"a", "a", "b", "b", "c"}
+	p := NewPoll(ReaderFunc(func(ctx context.Context) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		i := min(reads, len(seq)-1)
+		reads++
+		if reads == 2 {
+			return nil, errors.New("transient")
+		}
+		return []byte(seq[i]), nil
+	}), time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []string
+	err := p.Watch(ctx, func(data []byte) {
+		got = append(got, string(data))
+		if len(got) == 3 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Watch returned %v", err)
+	}
+	if len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestPoll_AdaptiveBacksOffWhenQuiet(t *testing.T) {
+	p := NewPoll(dummyProvider{b: []byte("same")}, 2*time.Millisecond, WithAdaptiveInterval(time.Millisecond, 5*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_ = p.Watch(ctx, func([]byte) {})
+	if p.Interval() != 5*time.Millisecond {
+		t.Fatalf("expected interval to back off to max, got %v", p.Interval())
+	}
+}
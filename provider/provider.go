@@ -17,3 +17,9 @@ type ReaderFunc func(ctx context.Context) ([]byte, error)
 func (f ReaderFunc) Read(ctx context.Context) ([]byte, error) {
 	return f(ctx)
 }
+
+// Writer is implemented by providers whose source can be written to, such as
+// a local file. Write replaces the entire configuration with data.
+type Writer interface {
+	Write(ctx context.Context, data []byte) error
+}
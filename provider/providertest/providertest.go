@@ -0,0 +1,305 @@
+// Package providertest implements a conformance suite for provider.Provider
+// implementations, so in-tree and third-party providers are verified against
+// the same contract:
+//
+//	func TestConformance(t *testing.T) {
+//		providertest.TestProvider(t, providertest.Harness{
+//			New: func(t *testing.T, data []byte) provider.Provider {
+//				path := filepath.Join(t.TempDir(), "config")
+//				_ = os.WriteFile(path, data, 0o600)
+//				return file.New(path)
+//			},
+//		})
+//	}
+package providertest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-sphere/confstore/provider"
+)
+
+// Harness adapts a provider implementation to the suite. Only New is
+// required; checks whose hooks are nil are skipped.
+type Harness struct {
+	// New returns a provider whose source holds exactly data.
+	New func(t *testing.T, data []byte) provider.Provider
+	// Missing returns a provider whose source does not exist. Read must fail
+	// with an error matching fs.ErrNotExist (see provider.NewNotFound).
+	Missing func(t *testing.T) provider.Provider
+	// Limited returns a provider whose source holds data and which is
+	// configured to reject documents larger than limit bytes.
+	Limited func(t *testing.T, data []byte, limit int64) provider.Provider
+	// LimitErr, if set, is the error Limited providers must return (under
+	// errors.Is) for oversized documents; otherwise any error is accepted.
+	LimitErr error
+	// HonorsCancellation requires Read to fail with an error matching
+	// context.Canceled when its context is already canceled. Providers that
+	// never block, such as local files, may leave it false; Read must still
+	// return promptly either way.
+	HonorsCancellation bool
+	// TextOnly restricts the suite to payloads that are valid UTF-8, for
+	// providers whose protocol carries documents in JSON strings.
+	TextOnly bool
+}
+
+// payloads are the documents every provider must return byte-for-byte.
+var payloads = map[string][]byte{
+	"json":   []byte(`{"addr":"0.0.0.0:8080","mode":"prod"}`),
+	"empty":  {},
+	"binary": {0x00, 0xff, 0xfe, '\n', '\r', 0x80},
+	"large":  bytes.Repeat([]byte("0123456789abcdef"), 1<<16), // 1 MiB
+}
+
+// timeout bounds every blocking operation of the suite.
+const timeout = 10 * time.Second
+
+// TestProvider runs the conformance suite as subtests of t. Optional
+// capabilities are exercised when the provider implements them:
+// provider.StreamProvider, provider.Writer, provider.ConditionalWriter and
+// provider.Watcher.
+func TestProvider(t *testing.T, h Harness) {
+	t.Helper()
+	if h.New == nil {
+		t.Fatal("providertest: Harness.New is required")
+	}
+	t.Run("Read", func(t *testing.T) { testRead(t, h) })
+	t.Run("ConcurrentRead", func(t *testing.T) { testConcurrentRead(t, h) })
+	t.Run("Cancellation", func(t *testing.T) { testCancellation(t, h) })
+	t.Run("NotFound", func(t *testing.T) {
+		if h.Missing == nil {
+			t.Skip("Harness.Missing not set")
+		}
+		_, err := h.Missing(t).Read(ctx(t))
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Read of missing source: got %v, want error matching fs.ErrNotExist", err)
+		}
+	})
+	t.Run("Limits", func(t *testing.T) { testLimits(t, h) })
+	t.Run("StreamProvider", func(t *testing.T) { testStreamProvider(t, h) })
+	t.Run("Writer", func(t *testing.T) { testWriter(t, h) })
+	t.Run("ConditionalWriter", func(t *testing.T) { testConditionalWriter(t, h) })
+	t.Run("Watcher", func(t *testing.T) { testWatcher(t, h) })
+}
+
+func ctx(t *testing.T) context.Context {
+	c, cancel := context.WithTimeout(context.Background(), timeout)
+	t.Cleanup(cancel)
+	return c
+}
+
+func testRead(t *testing.T, h Harness) {
+	for name, data := range payloads {
+		t.Run(name, func(t *testing.T) {
+			if h.TextOnly && !utf8.Valid(data) {
+				t.Skip("Harness.TextOnly set")
+			}
+			p := h.New(t, data)
+			for i := 0; i < 2; i++ {
+				got, err := p.Read(ctx(t))
+				if err != nil {
+					t.Fatalf("Read #%d: %v", i+1, err)
+				}
+				if !bytes.Equal(got, data) {
+					t.Fatalf("Read #%d returned %d bytes, want %d identical bytes", i+1, len(got), len(data))
+				}
+				if len(got) > 0 {
+					// Callers own the returned slice.
+					got[0] ^= 0xff
+				}
+			}
+		})
+	}
+}
+
+func testConcurrentRead(t *testing.T, h Harness) {
+	data := payloads["json"]
+	p := h.New(t, data)
+	c := ctx(t)
+	const goroutines, reads = 16, 4
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*reads)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < reads; j++ {
+				got, err := p.Read(c)
+				if err == nil && !bytes.Equal(got, data) {
+					err = errors.New("concurrent Read returned different content")
+				}
+				if err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func testCancellation(t *testing.T, h Harness) {
+	p := h.New(t, payloads["json"])
+	c, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.Read(c)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if h.HonorsCancellation && !errors.Is(err, context.Canceled) {
+			t.Fatalf("Read with canceled context: got %v, want error matching context.Canceled", err)
+		}
+	case <-time.After(timeout):
+		t.Fatal("Read with canceled context did not return")
+	}
+}
+
+func testLimits(t *testing.T, h Harness) {
+	if h.Limited == nil {
+		t.Skip("Harness.Limited not set")
+	}
+	const limit = 64
+	small := bytes.Repeat([]byte("a"), limit)
+	got, err := h.Limited(t, small, limit).Read(ctx(t))
+	if err != nil || !bytes.Equal(got, small) {
+		t.Fatalf("Read at the limit: got %d bytes, err %v", len(got), err)
+	}
+	_, err = h.Limited(t, append(small, 'a'), limit).Read(ctx(t))
+	switch {
+	case err == nil:
+		t.Fatal("Read over the limit succeeded")
+	case h.LimitErr != nil && !errors.Is(err, h.LimitErr):
+		t.Fatalf("Read over the limit: got %v, want error matching %v", err, h.LimitErr)
+	}
+}
+
+func testStreamProvider(t *testing.T, h Harness) {
+	data := payloads["large"]
+	p := h.New(t, data)
+	sp, ok := p.(provider.StreamProvider)
+	if !ok {
+		t.Skip("provider does not implement provider.StreamProvider")
+	}
+	r, err := sp.Open(ctx(t))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if cerr := r.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("streamed %d bytes, err %v; want %d identical bytes", len(got), err, len(data))
+	}
+	if h.Missing == nil {
+		return
+	}
+	if missing, ok := h.Missing(t).(provider.StreamProvider); ok {
+		if _, err = missing.Open(ctx(t)); !errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Open of missing source: got %v, want error matching fs.ErrNotExist", err)
+		}
+	}
+}
+
+func testWriter(t *testing.T, h Harness) {
+	p := h.New(t, payloads["json"])
+	w, ok := p.(provider.Writer)
+	if !ok {
+		t.Skip("provider does not implement provider.Writer")
+	}
+	want := []byte(`{"written":true}`)
+	if err := w.Write(ctx(t), want); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got, err := p.Read(ctx(t))
+	if err != nil || !bytes.Equal(got, want) {
+		t.Fatalf("Read after Write = %q, %v; want %q", got, err, want)
+	}
+}
+
+func testConditionalWriter(t *testing.T, h Harness) {
+	data := payloads["json"]
+	p := h.New(t, data)
+	w, ok := p.(provider.ConditionalWriter)
+	if !ok {
+		t.Skip("provider does not implement provider.ConditionalWriter")
+	}
+	got, v1, err := w.ReadVersion(ctx(t))
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("ReadVersion = %q, %v; want %q", got, err, data)
+	}
+	if _, again, _ := w.ReadVersion(ctx(t)); again != v1 {
+		t.Fatalf("version changed without a write: %q then %q", v1, again)
+	}
+	next := []byte(`{"next":true}`)
+	v2, err := w.WriteIf(ctx(t), next, v1)
+	if err != nil {
+		t.Fatalf("WriteIf with current version: %v", err)
+	}
+	if v2 == v1 {
+		t.Fatalf("WriteIf returned unchanged version %q", v2)
+	}
+	if _, err = w.WriteIf(ctx(t), data, v1); !errors.Is(err, provider.ErrConflict) {
+		t.Fatalf("WriteIf with stale version: got %v, want provider.ErrConflict", err)
+	}
+	if got, v, err := w.ReadVersion(ctx(t)); err != nil || !bytes.Equal(got, next) || v != v2 {
+		t.Fatalf("ReadVersion after WriteIf = %q, %q, %v; want %q, %q", got, v, err, next, v2)
+	}
+}
+
+func testWatcher(t *testing.T, h Harness) {
+	data := payloads["json"]
+	p := h.New(t, data)
+	w, ok := p.(provider.Watcher)
+	if !ok {
+		t.Skip("provider does not implement provider.Watcher")
+	}
+	c, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	first := make(chan []byte, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Watch(c, func(d []byte) {
+			select {
+			case first <- d:
+			default:
+			}
+		})
+	}()
+	select {
+	case got := <-first:
+		if !bytes.Equal(got, data) {
+			t.Fatalf("first Watch delivery = %q, want %q", got, data)
+		}
+	case err := <-done:
+		if errors.Is(err, provider.ErrWatchUnsupported) {
+			t.Skip("watch not configured for this provider")
+		}
+		t.Fatalf("Watch returned before delivering: %v", err)
+	case <-c.Done():
+		t.Fatal("Watch did not deliver the current configuration")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Watch after cancel: got %v, want context.Canceled", err)
+		}
+	case <-time.After(timeout):
+		t.Fatal("Watch did not return after its context was canceled")
+	}
+}
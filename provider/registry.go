@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownScheme indicates that no factory is registered for the scheme of a URI passed to Open.
+var ErrUnknownScheme = errors.New("provider: unknown scheme")
+
+// Factory builds a Provider from a parsed URI. Factories are registered by
+// scheme and receive the full URI so they can interpret host, path and query.
+type Factory func(ctx context.Context, uri *url.URL) (Provider, error)
+
+// Registry maps URI schemes to provider factories. It lets backends plug in
+// without this package importing them, and lets applications pick a source
+// purely from a configuration string such as "file:///etc/app.json".
+// A Registry is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register associates a factory with a scheme. Schemes are case-insensitive.
+// Like database/sql driver registration it is intended to be called from init
+// functions, so it panics if the factory is nil or the scheme is already taken.
+func (r *Registry) Register(scheme string, factory Factory) {
+	if factory == nil {
+		panic("provider: Register factory is nil")
+	}
+	scheme = strings.ToLower(scheme)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.factories[scheme]; dup {
+		panic("provider: Register called twice for scheme " + scheme)
+	}
+	r.factories[scheme] = factory
+}
+
+// Open parses uri and builds a Provider using the factory registered for its scheme.
+// A location without "://" (e.g. "./config.json", "C:\app\config.json" or
+// "conf:v1.json") is a file path: it is passed to the "file" factory as the
+// Path of a URL without being parsed, so colons and percent signs in file
+// names are taken literally.
+func (r *Registry) Open(ctx context.Context, uri string) (Provider, error) {
+	u := &url.URL{Path: uri}
+	if strings.Contains(uri, "://") {
+		var err error
+		if u, err = url.Parse(uri); err != nil {
+			return nil, fmt.Errorf("provider: parse uri %q: %w", uri, err)
+		}
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "" {
+		scheme = "file"
+	}
+	r.mu.RLock()
+	factory, ok := r.factories[scheme]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownScheme, scheme)
+	}
+	p, err := factory(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, fmt.Errorf("provider: factory for scheme %q returned no provider", scheme)
+	}
+	return p, nil
+}
+
+// Schemes returns the registered schemes in sorted order.
+func (r *Registry) Schemes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	schemes := make([]string, 0, len(r.factories))
+	for s := range r.factories {
+		schemes = append(schemes, s)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// DefaultRegistry is the registry used by the package-level Register and Open.
+// The built-in file and http providers register themselves here when imported.
+var DefaultRegistry = NewRegistry()
+
+// Register associates a factory with a scheme in DefaultRegistry.
+func Register(scheme string, factory Factory) {
+	DefaultRegistry.Register(scheme, factory)
+}
+
+// Open builds a Provider for uri using DefaultRegistry.
+func Open(ctx context.Context, uri string) (Provider, error) {
+	return DefaultRegistry.Open(ctx, uri)
+}
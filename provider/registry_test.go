@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestRegistry_OpenDispatchesByScheme(t *testing.T) {
+	r := NewRegistry()
+	var gotPath string
+	r.Register("mem", func(ctx context.Context, u *url.URL) (Provider, error) {
+		gotPath = u.Host + u.Path
+		return dummyProvider{b: []byte("mem")}, nil
+	})
+	p, err := r.Open(context.Background(), "MEM://bucket/app.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPath != "bucket/app.json" {
+		t.Fatalf("factory got %q, want %q", gotPath, "bucket/app.json")
+	}
+	data, _ := p.Read(context.Background())
+	if string(data) != "mem" {
+		t.Fatalf("got %q, want %q", string(data), "mem")
+	}
+}
+
+func TestRegistry_NoSchemeUsesFile(t *testing.T) {
+	r := NewRegistry()
+	r.Register("file", func(ctx context.Context, u *url.URL) (Provider, error) {
+		return dummyProvider{b: []byte(u.Path)}, nil
+	})
+	p, err := r.Open(context.Background(), "./config.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := p.Read(context.Background())
+	if string(data) != "./config.json" {
+		t.Fatalf("got %q, want %q", string(data), "./config.json")
+	}
+}
+
+func TestRegistry_UnknownScheme(t *testing.T) {
+	r := NewRegistry()
+	p, err := r.Open(context.Background(), "etcd://localhost/app")
+	if p != nil {
+		t.Fatalf("expected nil provider, got %#v", p)
+	}
+	if !errors.Is(err, ErrUnknownScheme) {
+		t.Fatalf("expected ErrUnknownScheme, got %v", err)
+	}
+}
+
+func TestRegistry_DuplicatePanics(t *testing.T) {
+	r := NewRegistry()
+	f := func(ctx context.Context, u *url.URL) (Provider, error) { return nil, nil }
+	r.Register("x", f)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on duplicate registration")
+		}
+	}()
+	r.Register("X", f)
+}
+
+func TestRegistry_WindowsDrivePathUsesFile(t *testing.T) {
+	r := NewRegistry()
+	r.Register("file", func(ctx context.Context, u *url.URL) (Provider, error) {
+		return dummyProvider{b: []byte(u.Path)}, nil
+	})
+	p, err := r.Open(context.Background(), `C:\conf\app.json`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := p.Read(context.Background())
+	if string(data) != `C:\conf\app.json` {
+		t.Fatalf("got %q", string(data))
+	}
+}
+
+func TestRegistry_PathsAreNotParsed(t *testing.T) {
+	r := NewRegistry()
+	r.Register("file", func(ctx context.Context, u *url.URL) (Provider, error) {
+		return dummyProvider{b: []byte(u.Path)}, nil
+	})
+	for _, loc := range []string{"conf:v1.json", "100%.json", "/etc/app?.json"} {
+		p, err := r.Open(context.Background(), loc)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", loc, err)
+		}
+		if data, _ := p.Read(context.Background()); string(data) != loc {
+			t.Fatalf("%s: factory got path %q", loc, data)
+		}
+	}
+}
+
+func TestRegistry_NilProvider(t *testing.T) {
+	r := NewRegistry()
+	r.Register("nil", func(ctx context.Context, u *url.URL) (Provider, error) { return nil, nil })
+	if p, err := r.Open(context.Background(), "nil://x"); p != nil || err == nil {
+		t.Fatalf("expected error for nil provider, got %v, %v", p, err)
+	}
+}
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrMirrorMismatch indicates that a mirror's content did not match the source after writing.
+var ErrMirrorMismatch = errors.New("provider: mirror content does not match source")
+
+// Replicator copies configuration from a primary source to one or more mirrors,
+// e.g. to keep a warm local file mirror of a central config service.
+// Mirrors that also implement Provider are read back after writing and
+// compared with the source to verify integrity. ConditionalWriter mirrors are
+// written with WriteIf and verified by version instead, so the check covers
+// the stored bytes even when Read transforms them (e.g. file.WithTrimBOM).
+// A Replicator is safe for concurrent use.
+type Replicator struct {
+	source  Provider
+	mirrors []Writer
+
+	mu     sync.Mutex
+	synced bool
+	digest [sha256.Size]byte
+}
+
+// NewReplicator creates a Replicator from source to mirrors.
+func NewReplicator(source Provider, mirrors ...Writer) *Replicator {
+	return &Replicator{source: source, mirrors: mirrors}
+}
+
+// Sync reads the source and, if its content changed since the last successful
+// Sync, writes it to every mirror and verifies them. It reports whether the
+// mirrors were written. Failures are joined per mirror; after a failure the
+// next Sync writes all mirrors again. Concurrent Syncs are serialized from the
+// source read on, so an older read can never overwrite a newer one.
+func (r *Replicator) Sync(ctx context.Context) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := r.source.Read(ctx)
+	if err != nil {
+		return false, fmt.Errorf("replicator: read source: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	if r.synced && digest == r.digest {
+		return false, nil
+	}
+	var joined error
+	for i, m := range r.mirrors {
+		if err = replicate(ctx, m, data); err != nil {
+			joined = errors.Join(joined, fmt.Errorf("mirror[%d]: %w", i, err))
+		}
+	}
+	if joined != nil {
+		r.synced = false
+		return true, fmt.Errorf("replicator: %w", joined)
+	}
+	r.synced, r.digest = true, digest
+	return true, nil
+}
+
+func replicate(ctx context.Context, m Writer, data []byte) error {
+	if cw, ok := m.(ConditionalWriter); ok {
+		return replicateIf(ctx, cw, data)
+	}
+	if err := m.Write(ctx, data); err != nil {
+		return err
+	}
+	p, ok := m.(Provider)
+	if !ok {
+		return nil
+	}
+	got, err := p.Read(ctx)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	if !bytes.Equal(got, data) {
+		return ErrMirrorMismatch
+	}
+	return nil
+}
+
+func replicateIf(ctx context.Context, m ConditionalWriter, data []byte) error {
+	written, err := RetryWithMerge(ctx, m, 3, func([]byte) ([]byte, error) { return data, nil })
+	if err != nil {
+		return err
+	}
+	_, version, err := m.ReadVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	if version != written {
+		return ErrMirrorMismatch
+	}
+	return nil
+}
+
+// Run calls Sync immediately and then every interval until ctx is done, and
+// returns ctx.Err(). Sync errors are passed to onError, which may be nil.
+// A non-positive interval defaults to one minute, as in NewPoll.
+func (r *Replicator) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Sync(ctx); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+type memMirror struct {
+	data    []byte
+	writes  int
+	corrupt bool
+}
+
+func (m *memMirror) Write(ctx context.Context, data []byte) error {
+	m.writes++
+	m.data = append([]byte(nil), data...)
+	if m.corrupt {
+		m.data = append(m.data, '!')
+	}
+	return nil
+}
+
+func (m *memMirror) Read(ctx context.Context) ([]byte, error) { return m.data, nil }
+
+func TestReplicator_SyncOnChange(t *testing.T) {
+	src := []byte("v1")
+	source := ReaderFunc(func(ctx context.Context) ([]byte, error) { return src, nil })
+	m1, m2 := &memMirror{}, &memMirror{}
+	r := NewReplicator(source, m1, m2)
+
+	changed, err := r.Sync(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("first sync: changed=%v err=%v", changed, err)
+	}
+	changed, err = r.Sync(context.Background())
+	if err != nil || changed {
+		t.Fatalf("unchanged sync: changed=%v err=%v", changed, err)
+	}
+	src = []byte("v2")
+	if _, err = r.Sync(context.Background()); err != nil {
+		t.Fatalf("sync error: %v", err)
+	}
+	if string(m1.data) != "v2" || string(m2.data) != "v2" || m1.writes != 2 {
+		t.Fatalf("unexpected mirrors: %q %q writes=%d", m1.data, m2.data, m1.writes)
+	}
+}
+
+func TestReplicator_VerifyMismatch(t *testing.T) {
+	source := dummyProvider{b: []byte("v1")}
+	bad := &memMirror{corrupt: true}
+	r := NewReplicator(source, bad)
+	if _, err := r.Sync(context.Background()); !errors.Is(err, ErrMirrorMismatch) {
+		t.Fatalf("expected ErrMirrorMismatch, got %v", err)
+	}
+	// Failed syncs are retried even though the source did not change.
+	bad.corrupt = false
+	changed, err := r.Sync(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("retry sync: changed=%v err=%v", changed, err)
+	}
+}
+
+func TestReplicator_ConcurrentSyncKeepsNewest(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	var mu sync.Mutex
+	reads := 0
+	source := ReaderFunc(func(ctx context.Context) ([]byte, error) {
+		mu.Lock()
+		reads++
+		n := reads
+		mu.Unlock()
+		if n == 1 {
+			close(entered)
+			<-release
+			return []byte("v1"), nil
+		}
+		return []byte("v2"), nil
+	})
+	mirror := &memMirror{}
+	r := NewReplicator(source, mirror)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); _, _ = r.Sync(context.Background()) }()
+	<-entered
+	go func() { defer wg.Done(); _, _ = r.Sync(context.Background()) }()
+	close(release)
+	wg.Wait()
+	if string(mirror.data) != "v2" {
+		t.Fatalf("stale read overwrote mirror: %q", mirror.data)
+	}
+}
+
+func TestReplicator_RunNonPositiveInterval(t *testing.T) {
+	mirror := &memMirror{}
+	r := NewReplicator(dummyProvider{b: []byte("v1")}, mirror)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.Run(ctx, 0, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// Singleflight wraps a provider so that concurrent Reads share one upstream
+// fetch: while a Read is in flight, further callers wait for its result
+// instead of issuing their own. This keeps lazily loaded config on request
+// paths from stampeding the source.
+//
+// The shared fetch runs with a context detached from any single caller (it
+// keeps their values) and is canceled only when every waiting caller has
+// given up. Each caller receives its own copy of the data. Only callers
+// carrying the same context values (see WithValues) share a fetch, since
+// providers may build their request from those values.
+type Singleflight struct {
+	provider Provider
+
+	mu    sync.Mutex
+	calls map[string]*flight // by valuesID
+}
+
+type flight struct {
+	key     string
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int // guarded by Singleflight.mu
+	data    []byte
+	err     error
+}
+
+// NewSingleflight wraps p in a Singleflight.
+func NewSingleflight(p Provider) *Singleflight {
+	return &Singleflight{provider: p, calls: make(map[string]*flight)}
+}
+
+// Read implements Provider.
+func (s *Singleflight) Read(ctx context.Context) ([]byte, error) {
+	key := valuesID(Values(ctx))
+	s.mu.Lock()
+	c := s.calls[key]
+	if c == nil {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &flight{key: key, done: make(chan struct{}), cancel: cancel}
+		s.calls[key] = c
+		go s.fetch(fctx, c)
+	}
+	c.waiters++
+	s.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return bytes.Clone(c.data), c.err
+	case <-ctx.Done():
+		s.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			// Abandoned: cancel it and let the next caller start a fresh flight.
+			c.cancel()
+			s.detach(c)
+		}
+		s.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (s *Singleflight) fetch(ctx context.Context, c *flight) {
+	c.data, c.err = s.provider.Read(ctx)
+	c.cancel()
+	s.mu.Lock()
+	s.detach(c)
+	s.mu.Unlock()
+	close(c.done)
+}
+
+// detach removes c so that later callers start a new flight. s.mu must be held.
+func (s *Singleflight) detach(c *flight) {
+	if s.calls[c.key] == c {
+		delete(s.calls, c.key)
+	}
+}
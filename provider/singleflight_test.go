@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleflight_Coalesces(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	s := NewSingleflight(ReaderFunc(func(ctx context.Context) ([]byte, error) {
+		calls.Add(1)
+		<-release
+		return []byte("cfg"), nil
+	}))
+
+	const readers = 10
+	var wg sync.WaitGroup
+	results := make(chan string, readers)
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := s.Read(context.Background())
+			if err != nil {
+				t.Errorf("Read error: %v", err)
+			}
+			results <- string(data)
+		}()
+	}
+	// Let every reader join the flight before it completes.
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mu.Lock()
+		c := s.calls[""]
+		joined := c != nil && c.waiters == readers
+		s.mu.Unlock()
+		if joined || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	close(results)
+	for r := range results {
+		if r != "cfg" {
+			t.Fatalf("unexpected result %q", r)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected 1 upstream read, got %d", n)
+	}
+
+	// Later reads start a new flight.
+	if _, err := s.Read(context.Background()); err != nil || calls.Load() != 2 {
+		t.Fatalf("second read: err=%v calls=%d", err, calls.Load())
+	}
+}
+
+func TestSingleflight_CancelsWhenAllWaitersLeave(t *testing.T) {
+	canceled := make(chan struct{})
+	s := NewSingleflight(ReaderFunc(func(ctx context.Context) ([]byte, error) {
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Read(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("upstream read not canceled after the last waiter left")
+	}
+}
+
+func TestSingleflight_KeepsTenantsApart(t *testing.T) {
+	release := make(chan struct{})
+	s := NewSingleflight(ReaderFunc(func(ctx context.Context) ([]byte, error) {
+		<-release
+		tenant, _ := Value(ctx, "tenant")
+		return []byte(tenant), nil
+	}))
+	var wg sync.WaitGroup
+	for _, tenant := range []string{"acme", "globex"} {
+		ctx := WithValues(context.Background(), map[string]string{"tenant": tenant})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := s.Read(ctx)
+			if err != nil || string(data) != tenant {
+				t.Errorf("%s got %q, %v", tenant, data, err)
+			}
+		}()
+	}
+	// Both tenants must be in flight at once for the check to be meaningful.
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mu.Lock()
+		n := len(s.calls)
+		s.mu.Unlock()
+		if n == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+}
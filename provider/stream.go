@@ -0,0 +1,16 @@
+package provider
+
+import (
+	"context"
+	"io"
+)
+
+// StreamProvider is implemented by providers that can return the
+// configuration as a stream, so very large documents can be decoded without
+// first being buffered in memory.
+type StreamProvider interface {
+	Provider
+	// Open returns a reader over the entire configuration. The caller must
+	// close it. The context governs the whole read, not just Open.
+	Open(ctx context.Context) (io.ReadCloser, error)
+}
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WithReadTimeout wraps p so that every Read is bounded by d, in addition to
+// any deadline on the caller's context. The wrapped provider receives a
+// context with the deadline; if it does not honor it (a local file on a hung
+// network mount, say), Read still returns once d elapses, leaving the
+// underlying call to finish in the background. The timeout error wraps
+// context.DeadlineExceeded. A non-positive d disables the timeout and returns
+// p unchanged, keeping any optional interfaces it implements.
+func WithReadTimeout(p Provider, d time.Duration) Provider {
+	if d <= 0 {
+		return p
+	}
+	return ReaderFunc(func(ctx context.Context) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		type result struct {
+			data []byte
+			err  error
+		}
+		done := make(chan result, 1)
+		go func() {
+			data, err := p.Read(ctx)
+			done <- result{data, err}
+		}()
+		select {
+		case r := <-done:
+			return r.data, r.err
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, fmt.Errorf("provider: read timed out after %s: %w", d, ctx.Err())
+			}
+			return nil, ctx.Err()
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidValue indicates a context value that cannot be substituted safely,
+// such as a path separator in a value used to build a file path.
+var ErrInvalidValue = errors.New("provider: invalid context value")
+
+type valuesKey struct{}
+
+// WithValues returns a copy of ctx carrying vals, merged over any values ctx
+// already carries. Multi-tenant services use it to pass identifiers such as
+// tenant or environment to providers, which substitute them into "{key}"
+// placeholders (see ExpandValues):
+//
+//	ctx = provider.WithValues(ctx, map[string]string{"tenant": "acme"})
+//	data, err := confhttp.New("https://cfg.internal/configs/{tenant}.json").Read(ctx)
+func WithValues(ctx context.Context, vals map[string]string) context.Context {
+	merged := maps.Clone(Values(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(vals))
+	}
+	maps.Copy(merged, vals)
+	return context.WithValue(ctx, valuesKey{}, merged)
+}
+
+// Values returns the values carried by ctx. The map must not be modified.
+func Values(ctx context.Context) map[string]string {
+	vals, _ := ctx.Value(valuesKey{}).(map[string]string)
+	return vals
+}
+
+// Value returns the value for key carried by ctx.
+func Value(ctx context.Context, key string) (string, bool) {
+	v, ok := Values(ctx)[key]
+	return v, ok
+}
+
+// valuesID returns a canonical string for vals, for wrappers that share or
+// cache results and must keep callers with different values apart.
+func valuesID(vals map[string]string) string {
+	if len(vals) == 0 {
+		return ""
+	}
+	keys := slices.Sorted(maps.Keys(vals))
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(strconv.Quote(k) + "=" + strconv.Quote(vals[k]) + ";")
+	}
+	return b.String()
+}
+
+// ExpandValues replaces "{key}" placeholders in s with the values carried by
+// ctx, passing each through escape if it is non-nil. Placeholders without a
+// value are left unchanged, so strings that happen to contain braces keep
+// working when no values are set.
+func ExpandValues(ctx context.Context, s string, escape func(string) (string, error)) (string, error) {
+	vals := Values(ctx)
+	if len(vals) == 0 || !strings.Contains(s, "{") {
+		return s, nil
+	}
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(s, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(s[open:], '}')
+		if end < 0 {
+			break
+		}
+		key := s[open+1 : open+end]
+		v, ok := vals[key]
+		if !ok {
+			b.WriteString(s[:open+1])
+			s = s[open+1:]
+			continue
+		}
+		if escape != nil {
+			var err error
+			if v, err = escape(v); err != nil {
+				return "", fmt.Errorf("%w: %s: %w", ErrInvalidValue, key, err)
+			}
+		}
+		b.WriteString(s[:open] + v)
+		s = s[open+end+1:]
+	}
+	b.WriteString(s)
+	return b.String(), nil
+}
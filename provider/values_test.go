@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithValues(t *testing.T) {
+	ctx := WithValues(context.Background(), map[string]string{"tenant": "acme", "env": "dev"})
+	ctx = WithValues(ctx, map[string]string{"env": "prod"})
+	if v, _ := Value(ctx, "tenant"); v != "acme" {
+		t.Fatalf("tenant = %q", v)
+	}
+	if v, _ := Value(ctx, "env"); v != "prod" {
+		t.Fatalf("env = %q, want override", v)
+	}
+	if _, ok := Value(context.Background(), "tenant"); ok {
+		t.Fatal("unexpected value in empty context")
+	}
+}
+
+func TestExpandValues(t *testing.T) {
+	ctx := WithValues(context.Background(), map[string]string{"tenant": "acme", "env": "prod"})
+	got, err := ExpandValues(ctx, "/configs/{env}/{tenant}.json?v={unknown}", nil)
+	if err != nil || got != "/configs/prod/acme.json?v={unknown}" {
+		t.Fatalf("ExpandValues = %q, %v", got, err)
+	}
+	if got, _ := ExpandValues(context.Background(), "{tenant}", nil); got != "{tenant}" {
+		t.Fatalf("expected no expansion without values, got %q", got)
+	}
+	_, err = ExpandValues(ctx, "{tenant}", func(v string) (string, error) { return "", errors.New("nope") })
+	if !errors.Is(err, ErrInvalidValue) || !strings.Contains(err.Error(), "tenant") {
+		t.Fatalf("expected ErrInvalidValue naming the key, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrVersionUnsupported indicates that a provider cannot read historical versions.
+var ErrVersionUnsupported = errors.New("provider: versioned reads not supported")
+
+// VersionedProvider is implemented by providers backed by a store that keeps
+// history, such as S3 object versions, etcd revisions or git refs.
+type VersionedProvider interface {
+	Provider
+	// ReadAt returns the configuration as it was at the given version. The
+	// version format is backend-specific; backends that can resolve points in
+	// time should document whether they also accept RFC 3339 timestamps.
+	ReadAt(ctx context.Context, version string) ([]byte, error)
+}
+
+// ReadAt reads the given version from p if it implements VersionedProvider,
+// and returns ErrVersionUnsupported otherwise.
+func ReadAt(ctx context.Context, p Provider, version string) ([]byte, error) {
+	v, ok := p.(VersionedProvider)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrVersionUnsupported, p)
+	}
+	return v.ReadAt(ctx, version)
+}
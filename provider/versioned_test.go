@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type historyProvider struct {
+	current  []byte
+	versions map[string][]byte
+}
+
+func (h historyProvider) Read(ctx context.Context) ([]byte, error) { return h.current, nil }
+
+func (h historyProvider) ReadAt(ctx context.Context, version string) ([]byte, error) {
+	data, ok := h.versions[version]
+	if !ok {
+		return nil, errors.New("no such version")
+	}
+	return data, nil
+}
+
+func TestReadAt_Unsupported(t *testing.T) {
+	_, err := ReadAt(context.Background(), dummyProvider{b: []byte("x")}, "v1")
+	if !errors.Is(err, ErrVersionUnsupported) {
+		t.Fatalf("expected ErrVersionUnsupported, got %v", err)
+	}
+}
+
+func TestExpandEnv_ReadAtForwards(t *testing.T) {
+	t.Setenv("FOO", "BAR")
+	h := historyProvider{current: []byte("now"), versions: map[string][]byte{"v1": []byte("old=${FOO}")}}
+	got, err := ReadAt(context.Background(), NewExpandEnv(h), "v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "old=BAR" {
+		t.Fatalf("got %q, want %q", string(got), "old=BAR")
+	}
+	_, err = NewExpandEnv(dummyProvider{}).ReadAt(context.Background(), "v1")
+	if !errors.Is(err, ErrVersionUnsupported) {
+		t.Fatalf("expected ErrVersionUnsupported, got %v", err)
+	}
+}
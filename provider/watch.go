@@ -0,0 +1,20 @@
+package provider
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrWatchUnsupported indicates that a provider is not configured to watch for updates.
+var ErrWatchUnsupported = errors.New("provider: watch not supported")
+
+// Watcher is implemented by providers that can push configuration updates
+// instead of being polled with Read.
+type Watcher interface {
+	Provider
+	// Watch blocks and calls onChange with each new version of the raw
+	// configuration until ctx is done or the watch fails permanently.
+	// onChange is called sequentially and should return promptly.
+	// Watch returns ctx.Err() when ctx is canceled.
+	Watch(ctx context.Context, onChange func(data []byte)) error
+}
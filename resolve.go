@@ -0,0 +1,237 @@
+package confstore
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sphere/confstore/provider"
+)
+
+var (
+	// ErrUnknownResolver indicates that a conf tag names a scheme no resolver handles.
+	ErrUnknownResolver = errors.New("confstore: unknown resolver scheme")
+	// ErrRefNotFound is returned by resolvers when a reference does not exist.
+	// It matches fs.ErrNotExist, so missing files are treated alike.
+	ErrRefNotFound = provider.NewNotFound("confstore: reference not found")
+)
+
+// Resolver fetches values for struct fields tagged with its scheme.
+type Resolver interface {
+	Scheme() string
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+type resolverFunc struct {
+	scheme string
+	fn     func(ctx context.Context, ref string) (string, error)
+}
+
+func (r resolverFunc) Scheme() string { return r.scheme }
+
+func (r resolverFunc) Resolve(ctx context.Context, ref string) (string, error) { return r.fn(ctx, ref) }
+
+// ResolverFunc adapts a function to a Resolver for the given scheme, e.g. a
+// Vault client lookup for "vault".
+func ResolverFunc(scheme string, fn func(ctx context.Context, ref string) (string, error)) Resolver {
+	return resolverFunc{scheme: scheme, fn: fn}
+}
+
+// EnvResolver resolves "env:NAME" from the environment.
+func EnvResolver() Resolver {
+	return ResolverFunc("env", func(_ context.Context, name string) (string, error) {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("%w: environment variable %s", ErrRefNotFound, name)
+		}
+		return v, nil
+	})
+}
+
+// FileResolver resolves "file:/path" to the file's content with one trailing
+// newline removed, which suits mounted secrets such as /run/secrets/db.
+func FileResolver() Resolver {
+	return ResolverFunc("file", func(_ context.Context, path string) (string, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		s := strings.TrimSuffix(string(data), "\n")
+		return strings.TrimSuffix(s, "\r"), nil
+	})
+}
+
+// Resolve fills struct fields that declare their own source with a conf tag,
+// typically after the base document has been decoded:
+//
+//	type AppConf struct {
+//		Addr     string `json:"addr"`
+//		Port     int    `json:"port" conf:"env:PORT,optional"`
+//		Password string `json:"password" conf:"vault:secret/db#password"`
+//	}
+//
+// The tag value is "scheme:ref", dispatched to the resolver for that scheme.
+// With the optional flag, a reference that does not exist (an error matching
+// fs.ErrNotExist, such as ErrRefNotFound) leaves the field unchanged.
+// Resolved strings are converted to the field type: strings, booleans,
+// numbers, time.Duration and encoding.TextUnmarshaler are supported. Nested
+// structs, pointers to structs and slices or maps of structs are walked.
+// All failures are returned joined, each prefixed with the field path.
+func Resolve(ctx context.Context, config any, resolvers ...Resolver) error {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("confstore: resolve target must be a non-nil pointer, got %T", config)
+	}
+	byScheme := make(map[string]Resolver, len(resolvers))
+	for _, r := range resolvers {
+		byScheme[r.Scheme()] = r
+	}
+	r := &fieldResolver{resolvers: byScheme}
+	r.walk(ctx, v.Elem(), "")
+	return errors.Join(r.errs...)
+}
+
+type fieldResolver struct {
+	resolvers map[string]Resolver
+	errs      []error
+}
+
+func (r *fieldResolver) walk(ctx context.Context, v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			r.walk(ctx, v.Elem(), path)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() && !f.Anonymous {
+				continue
+			}
+			fv := v.Field(i)
+			name := f.Name
+			if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag != "" && tag != "-" {
+				name = tag
+			}
+			fieldPath := path
+			if !f.Anonymous {
+				fieldPath = joinPath(path, name)
+			}
+			if tag, ok := f.Tag.Lookup("conf"); ok && fv.CanSet() {
+				if err := r.resolveField(ctx, fv, tag); err != nil {
+					r.errs = append(r.errs, fmt.Errorf("%s: %w", fieldPath, err))
+				}
+				continue
+			}
+			if fv.CanSet() || f.Anonymous {
+				r.walk(ctx, fv, fieldPath)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			r.walk(ctx, v.Index(i), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := iter.Value()
+			// Map values are not addressable: resolve a copy and store it back.
+			cp := reflect.New(elem.Type()).Elem()
+			cp.Set(elem)
+			before := len(r.errs)
+			r.walk(ctx, cp, joinPath(path, fmt.Sprint(iter.Key().Interface())))
+			if len(r.errs) == before {
+				v.SetMapIndex(iter.Key(), cp)
+			}
+		}
+	}
+}
+
+func (r *fieldResolver) resolveField(ctx context.Context, fv reflect.Value, tag string) error {
+	spec, flags, _ := strings.Cut(tag, ",")
+	scheme, ref, ok := strings.Cut(spec, ":")
+	if !ok || scheme == "" || ref == "" {
+		return fmt.Errorf("confstore: invalid conf tag %q, want \"scheme:ref\"", tag)
+	}
+	res, ok := r.resolvers[scheme]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownResolver, scheme)
+	}
+	s, err := res.Resolve(ctx, ref)
+	if err != nil {
+		if flags == "optional" && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return setString(fv, s)
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// setString converts s to fv's type and stores it.
+func setString(fv reflect.Value, s string) error {
+	if fv.Kind() == reflect.Pointer {
+		ptr := reflect.New(fv.Type().Elem())
+		if err := setString(ptr.Elem(), s); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	if reflect.PointerTo(fv.Type()).Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if fv.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("confstore: cannot resolve into %s", fv.Type())
+		}
+		fv.SetBytes([]byte(s))
+	default:
+		return fmt.Errorf("confstore: cannot resolve into %s", fv.Type())
+	}
+	return nil
+}
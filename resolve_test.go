@@ -0,0 +1,93 @@
+package confstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type resolveDB struct {
+	Host     string `json:"host"`
+	Password string `json:"password" conf:"vault:secret/db#password"`
+}
+
+type resolveConf struct {
+	Addr    string               `json:"addr"`
+	Port    int                  `json:"port" conf:"env:RESOLVE_TEST_PORT"`
+	Debug   *bool                `json:"debug" conf:"env:RESOLVE_TEST_DEBUG,optional"`
+	Timeout time.Duration        `json:"timeout" conf:"env:RESOLVE_TEST_TIMEOUT,optional"`
+	Token   string               `json:"token" conf:"file:/run/secrets/token"`
+	DB      resolveDB            `json:"db"`
+	Shards  map[string]resolveDB `json:"shards"`
+}
+
+func vault(secrets map[string]string) Resolver {
+	return ResolverFunc("vault", func(_ context.Context, ref string) (string, error) {
+		v, ok := secrets[ref]
+		if !ok {
+			return "", ErrRefNotFound
+		}
+		return v, nil
+	})
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("RESOLVE_TEST_PORT", "8080")
+	file := ResolverFunc("file", func(_ context.Context, ref string) (string, error) { return "s3cr3t", nil })
+
+	conf := resolveConf{Addr: ":80", Timeout: time.Second, DB: resolveDB{Host: "db"}, Shards: map[string]resolveDB{"a": {Host: "a"}}}
+	err := Resolve(context.Background(), &conf, EnvResolver(), file, vault(map[string]string{"secret/db#password": "pw"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conf.Port != 8080 || conf.Token != "s3cr3t" || conf.DB.Password != "pw" || conf.Shards["a"].Password != "pw" {
+		t.Fatalf("fields not resolved: %+v", conf)
+	}
+	if conf.Addr != ":80" || conf.DB.Host != "db" || conf.Debug != nil || conf.Timeout != time.Second {
+		t.Fatalf("untagged or optional fields changed: %+v", conf)
+	}
+
+	t.Setenv("RESOLVE_TEST_DEBUG", "true")
+	t.Setenv("RESOLVE_TEST_TIMEOUT", "5s")
+	if err = Resolve(context.Background(), &conf, EnvResolver(), file, vault(map[string]string{"secret/db#password": "pw"})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if conf.Debug == nil || !*conf.Debug || conf.Timeout != 5*time.Second {
+		t.Fatalf("optional fields not resolved: %+v", conf)
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	t.Setenv("RESOLVE_TEST_PORT", "not-a-number")
+	var conf resolveConf
+	err := Resolve(context.Background(), &conf, EnvResolver(), vault(nil))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !errors.Is(err, ErrUnknownResolver) || !errors.Is(err, ErrRefNotFound) {
+		t.Fatalf("expected unknown scheme and missing ref errors, got %v", err)
+	}
+	for _, path := range []string{"port:", "token:", "db.password:"} {
+		if !strings.Contains(err.Error(), path) {
+			t.Fatalf("error %q does not mention %s", err, path)
+		}
+	}
+}
+
+func TestFileResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatalf("write token: %v", err)
+	}
+	if v, err := FileResolver().Resolve(context.Background(), path); err != nil || v != "s3cr3t" {
+		t.Fatalf("Resolve = %q, %v", v, err)
+	}
+	_, err := FileResolver().Resolve(context.Background(), path+".missing")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not-exist error, got %v", err)
+	}
+}
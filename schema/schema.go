@@ -0,0 +1,375 @@
+// Package schema generates JSON Schema documents from Go configuration structs
+// and validates configuration files against them.
+//
+// Property names follow `json` struct tags. The following additional tags are recognized:
+//
+//	default:"8080"        default value, parsed according to the field type
+//	enum:"dev,prod"       comma-separated list of allowed values
+//	required:"true"       the property must be present
+//	description:"..."     human-readable description shown by editors
+//	examples:"a,b"        comma-separated example values, parsed like default
+//	deprecated:"..."      marks the property deprecated; any value other than "true" is used as the message
+//
+// Descriptions and deprecations are also emitted in the forms understood by
+// yaml-language-server/VS Code (markdownDescription, deprecationMessage) and
+// IntelliJ (x-intellij-html-description), so editors offer completion and
+// inline documentation for files that confstore will load.
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"html"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect emitted by Generate.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a subset of JSON Schema sufficient to describe configuration structs.
+// AdditionalProperties is nil (any extra property allowed), a *Schema
+// constraining extra properties, or false (no extra properties allowed);
+// Generate sets it to false for structs. Nullable additionally admits null;
+// it is encoded as a type array, e.g. "type": ["object", "null"].
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Examples             []any              `json:"examples,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+
+	// Editor-specific annotations.
+	MarkdownDescription     string `json:"markdownDescription,omitempty"`
+	DeprecationMessage      string `json:"deprecationMessage,omitempty"`
+	IntellijHTMLDescription string `json:"x-intellij-html-description,omitempty"`
+
+	Nullable bool `json:"-"`
+}
+
+type schemaJSON Schema
+
+// MarshalJSON implements json.Marshaler, encoding Nullable in "type".
+func (s Schema) MarshalJSON() ([]byte, error) {
+	out := struct {
+		*schemaJSON
+		Type any `json:"type,omitempty"`
+	}{schemaJSON: (*schemaJSON)(&s)}
+	if s.Type != "" {
+		out.Type = s.Type
+		if s.Nullable {
+			out.Type = []string{s.Type, "null"}
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting "type" as a string or
+// as a type array of one type plus "null".
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	in := struct {
+		*schemaJSON
+		Type json.RawMessage `json:"type,omitempty"`
+	}{schemaJSON: (*schemaJSON)(s)}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	s.Type, s.Nullable = "", false
+	if len(in.Type) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(in.Type, &s.Type); err == nil {
+		return nil
+	}
+	var types []string
+	if err := json.Unmarshal(in.Type, &types); err != nil {
+		return fmt.Errorf("schema: invalid type %s", in.Type)
+	}
+	for _, t := range types {
+		switch {
+		case t == "null":
+			s.Nullable = true
+		case s.Type == "":
+			s.Type = t
+		default:
+			return fmt.Errorf("schema: unsupported type union %s", in.Type)
+		}
+	}
+	return nil
+}
+
+type options struct {
+	id string
+}
+
+// Option configures schema generation.
+type Option func(*options)
+
+// WithID sets the root "$id". By default named types get a stable URN derived
+// from their import path and name, e.g. "urn:go:example.com/app/config.AppConf".
+func WithID(id string) Option { return func(o *options) { o.id = id } }
+
+// For generates the schema for type T.
+func For[T any](opts ...Option) (*Schema, error) {
+	return generate(reflect.TypeOf((*T)(nil)).Elem(), opts)
+}
+
+// Generate generates the schema for the dynamic type of v, which is usually a
+// config struct or a pointer to one.
+func Generate(v any, opts ...Option) (*Schema, error) {
+	if v == nil {
+		return nil, fmt.Errorf("schema: cannot generate schema for nil")
+	}
+	return generate(reflect.TypeOf(v), opts)
+}
+
+func generate(t reflect.Type, opts []Option) (*Schema, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	g := &generator{visiting: make(map[reflect.Type]bool)}
+	s, err := g.typeSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	// The document itself is never null, even when generated from a pointer.
+	s.Schema, s.Nullable = Draft, false
+	s.ID = o.id
+	if s.ID == "" {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Name() != "" && t.PkgPath() != "" {
+			s.ID = "urn:go:" + t.PkgPath() + "." + t.Name()
+		}
+	}
+	return s, nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+type generator struct {
+	visiting map[reflect.Type]bool
+}
+
+// typeSchema returns the schema for t. Pointers, maps and slices are
+// nullable, since encoding/json encodes their nil values as null.
+func (g *generator) typeSchema(t reflect.Type) (*Schema, error) {
+	nullable := t.Kind() == reflect.Pointer || t.Kind() == reflect.Map || t.Kind() == reflect.Slice
+	s, err := g.valueSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	s.Nullable = nullable && s.Type != ""
+	return s, nil
+}
+
+func (g *generator) valueSchema(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case t == durationType:
+		return &Schema{Type: "integer"}, nil
+	case implements(t, jsonMarshalerType) || implements(t, jsonUnmarshalerType):
+		// Custom JSON encoding; the shape cannot be derived from the Go type.
+		return &Schema{}, nil
+	case implements(t, textMarshalerType):
+		return &Schema{Type: "string"}, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices, but not byte arrays, as base64 strings.
+			return &Schema{Type: "string"}, nil
+		}
+		items, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String && !implements(t.Key(), textMarshalerType) {
+			return nil, fmt.Errorf("schema: unsupported map key type %s", t.Key())
+		}
+		values, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return g.structSchema(t)
+	case reflect.Interface:
+		return &Schema{}, nil
+	default:
+		return nil, fmt.Errorf("schema: unsupported type %s", t)
+	}
+}
+
+func (g *generator) structSchema(t reflect.Type) (*Schema, error) {
+	if g.visiting[t] {
+		// Recursive type: leave the nested value unconstrained.
+		return &Schema{Type: "object"}, nil
+	}
+	g.visiting[t] = true
+	defer delete(g.visiting, t)
+
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+	if err := g.addFields(s, t); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (g *generator) addFields(s *Schema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			// Embedded struct without a json name: its fields are inlined.
+			if err := g.addFields(s, ft); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop, err := g.typeSchema(f.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if err = applyTags(prop, f, ft); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		s.Properties[name] = prop
+		if f.Tag.Get("required") == "true" {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+// fieldName returns the json property name of f, or "" if the field has no
+// explicit name. ok is false for fields that encoding/json skips.
+func fieldName(f reflect.StructField) (name string, ok bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if !f.IsExported() && !f.Anonymous {
+		return "", false
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	return name, true
+}
+
+func applyTags(s *Schema, f reflect.StructField, t reflect.Type) error {
+	if def, ok := f.Tag.Lookup("default"); ok {
+		v, err := parseTagValue(def, t)
+		if err != nil {
+			return fmt.Errorf("default tag: %w", err)
+		}
+		s.Default = v
+	}
+	if enum, ok := f.Tag.Lookup("enum"); ok {
+		for _, raw := range strings.Split(enum, ",") {
+			v, err := parseTagValue(strings.TrimSpace(raw), t)
+			if err != nil {
+				return fmt.Errorf("enum tag: %w", err)
+			}
+			s.Enum = append(s.Enum, v)
+		}
+	}
+	if examples, ok := f.Tag.Lookup("examples"); ok {
+		for _, raw := range strings.Split(examples, ",") {
+			v, err := parseTagValue(strings.TrimSpace(raw), t)
+			if err != nil {
+				return fmt.Errorf("examples tag: %w", err)
+			}
+			s.Examples = append(s.Examples, v)
+		}
+	}
+	if desc := f.Tag.Get("description"); desc != "" {
+		s.Description = desc
+		s.MarkdownDescription = desc
+		s.IntellijHTMLDescription = html.EscapeString(desc)
+	}
+	if dep, ok := f.Tag.Lookup("deprecated"); ok && dep != "false" {
+		s.Deprecated = true
+		s.DeprecationMessage = "Deprecated."
+		if dep != "" && dep != "true" {
+			s.DeprecationMessage = dep
+		}
+	}
+	return nil
+}
+
+// parseTagValue converts a tag string into a JSON value matching type t.
+func parseTagValue(raw string, t reflect.Type) (any, error) {
+	if t == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, err
+		}
+		return int64(d), nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(raw, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(raw, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, 64)
+	default:
+		var v any
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+}
+
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
@@ -0,0 +1,195 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type dbConf struct {
+	DSN     string        `json:"dsn" required:"true"`
+	Timeout time.Duration `json:"timeout" default:"5s"`
+}
+
+type appConf struct {
+	Addr  string            `json:"addr" default:":8080"`
+	Mode  string            `json:"mode" enum:"dev,prod" required:"true"`
+	Port  int               `json:"port" default:"8080"`
+	DB    *dbConf           `json:"db"`
+	Tags  []string          `json:"tags,omitempty"`
+	Extra map[string]string `json:"extra"`
+	skip  int
+}
+
+func TestGenerate(t *testing.T) {
+	s, err := For[appConf]()
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	if s.Schema != Draft || s.Type != "object" {
+		t.Fatalf("unexpected root: %+v", s)
+	}
+	if s.Properties["mode"].Enum[1] != "prod" {
+		t.Fatalf("unexpected enum: %v", s.Properties["mode"].Enum)
+	}
+	if s.Properties["port"].Default != int64(8080) {
+		t.Fatalf("unexpected default: %#v", s.Properties["port"].Default)
+	}
+	if s.Properties["db"].Properties["timeout"].Default != int64(5*time.Second) {
+		t.Fatalf("unexpected duration default: %#v", s.Properties["db"].Properties["timeout"].Default)
+	}
+	if _, ok := s.Properties["skip"]; ok {
+		t.Fatal("unexported field should be skipped")
+	}
+	out, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	if !strings.Contains(string(out), `"additionalProperties":false`) || !strings.Contains(string(out), `"required":["mode"]`) {
+		t.Fatalf("unexpected schema json: %s", out)
+	}
+}
+
+func TestValidateAgainstSchema(t *testing.T) {
+	s, err := For[appConf]()
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	if err = s.ValidateAgainstSchema([]byte(`{"mode":"dev","port":80,"db":{"dsn":"x"},"extra":{"a":"b"}}`)); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	err = s.ValidateAgainstSchema([]byte(`{"mode":"qa","port":"80","db":{},"unknown":1}`))
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+	for _, want := range []string{
+		`$.mode: value qa is not one of [dev prod]`,
+		`$.port: expected integer, got string`,
+		`$.db: missing required property "dsn"`,
+		`$.unknown: unknown property`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q lacks %q", err.Error(), want)
+		}
+	}
+}
+
+type annotatedConf struct {
+	Addr   string `json:"addr" description:"Listen address, e.g. :8080" examples:":8080,127.0.0.1:9000"`
+	Listen string `json:"listen" deprecated:"use addr instead"`
+	Old    bool   `json:"old" deprecated:"true"`
+}
+
+func TestGenerateEditorAnnotations(t *testing.T) {
+	s, err := For[annotatedConf]()
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	if s.ID != "urn:go:github.com/go-sphere/confstore/schema.annotatedConf" {
+		t.Fatalf("unexpected $id: %q", s.ID)
+	}
+	addr := s.Properties["addr"]
+	if addr.Description == "" || addr.MarkdownDescription != addr.Description || addr.IntellijHTMLDescription == "" {
+		t.Fatalf("missing descriptions: %+v", addr)
+	}
+	if len(addr.Examples) != 2 || addr.Examples[1] != "127.0.0.1:9000" {
+		t.Fatalf("unexpected examples: %v", addr.Examples)
+	}
+	listen := s.Properties["listen"]
+	if !listen.Deprecated || listen.DeprecationMessage != "use addr instead" {
+		t.Fatalf("unexpected deprecation: %+v", listen)
+	}
+	if old := s.Properties["old"]; !old.Deprecated || old.DeprecationMessage != "Deprecated." {
+		t.Fatalf("unexpected deprecation: %+v", old)
+	}
+
+	s, err = For[annotatedConf](WithID("https://example.com/app.schema.json"))
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	out, _ := json.Marshal(s)
+	if !strings.Contains(string(out), `"$id":"https://example.com/app.schema.json"`) {
+		t.Fatalf("unexpected schema json: %s", out)
+	}
+}
+
+func TestGenerateByteSlicesAndArrays(t *testing.T) {
+	type conf struct {
+		Key  []byte  `json:"key"`
+		Hash [4]byte `json:"hash"`
+	}
+	s, err := For[conf]()
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	if s.Properties["key"].Type != "string" {
+		t.Fatalf("byte slice should be a base64 string: %+v", s.Properties["key"])
+	}
+	if hash := s.Properties["hash"]; hash.Type != "array" || hash.Items == nil || hash.Items.Type != "integer" {
+		t.Fatalf("byte array should be an array of integers: %+v", hash)
+	}
+	if err = s.ValidateAgainstSchema([]byte(`{"key":"AQI=","hash":[1,2,3,4]}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNullableReferenceTypes(t *testing.T) {
+	s, err := Generate(&appConf{})
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	if s.Nullable || !s.Properties["db"].Nullable || !s.Properties["tags"].Nullable || s.Properties["port"].Nullable {
+		t.Fatalf("unexpected nullability: %+v", s)
+	}
+	if err = s.ValidateAgainstSchema([]byte(`{"mode":"dev","db":null,"tags":null,"extra":null}`)); err != nil {
+		t.Fatalf("null should be allowed for pointer, slice and map fields: %v", err)
+	}
+	if err = s.ValidateAgainstSchema([]byte(`{"mode":"dev","port":null}`)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("null should be rejected for int fields, got %v", err)
+	}
+	out, err := json.Marshal(s.Properties["db"])
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	if !strings.Contains(string(out), `"type":["object","null"]`) {
+		t.Fatalf("unexpected schema json: %s", out)
+	}
+	var back Schema
+	if err = json.Unmarshal(out, &back); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if back.Type != "object" || !back.Nullable || back.Properties["dsn"].Type != "string" {
+		t.Fatalf("unexpected round trip: %+v", back)
+	}
+}
+
+func TestValidateMatchesKeysCaseInsensitively(t *testing.T) {
+	s, err := For[appConf]()
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	if err = s.ValidateAgainstSchema([]byte(`{"Mode":"dev","PORT":80,"db":{"DSN":"x"}}`)); err != nil {
+		t.Fatalf("keys accepted by encoding/json should validate: %v", err)
+	}
+	if err = s.ValidateAgainstSchema([]byte(`{"Mode":"dev","PORT":"80"}`)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("case-folded keys should still be type checked, got %v", err)
+	}
+}
+
+func TestValidateIntegerLiterals(t *testing.T) {
+	s, err := For[appConf]()
+	if err != nil {
+		t.Fatalf("generate error: %v", err)
+	}
+	for _, port := range []string{"1.0", "1e3", "8080.5"} {
+		err = s.ValidateAgainstSchema([]byte(`{"mode":"dev","port":` + port + `}`))
+		if err == nil || !strings.Contains(err.Error(), "$.port: expected integer, got number") {
+			t.Errorf("port %s: expected integer type error, got %v", port, err)
+		}
+	}
+	if err = s.ValidateAgainstSchema([]byte(`{"mode":"dev","port":-80}`)); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ErrInvalid indicates that a document does not conform to a schema.
+// Validation errors wrap it together with one error per violation.
+var ErrInvalid = errors.New("schema: document does not match schema")
+
+// ViolationKind classifies a schema violation.
+type ViolationKind string
+
+const (
+	// KindType reports a value of the wrong JSON type.
+	KindType ViolationKind = "type"
+	// KindEnum reports a value outside the allowed enum.
+	KindEnum ViolationKind = "enum"
+	// KindRequired reports a missing required property; Path is the enclosing object.
+	KindRequired ViolationKind = "required"
+	// KindUnknown reports a property not declared by the schema.
+	KindUnknown ViolationKind = "unknown"
+)
+
+// Violation describes a single place where a document does not match the schema.
+// Path uses "$" for the root, ".name" for properties and "[i]" for array items.
+type Violation struct {
+	Path    string
+	Kind    ViolationKind
+	Message string
+}
+
+// Error implements error.
+func (v Violation) Error() string {
+	return v.Path + ": " + v.Message
+}
+
+// ValidateAgainstSchema checks a JSON document against the schema and reports
+// every violation found, each prefixed with the JSON path of the offending value.
+// Property names match case-insensitively when there is no exact match, as
+// in encoding/json, so documents that load are not reported as invalid.
+func (s *Schema) ValidateAgainstSchema(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("schema: parse document: %w", err)
+	}
+	violations := s.Violations(doc)
+	if len(violations) == 0 {
+		return nil
+	}
+	errs := []error{ErrInvalid}
+	for _, v := range violations {
+		errs = append(errs, v)
+	}
+	return errors.Join(errs...)
+}
+
+// Violations checks an already-decoded document against the schema. doc is
+// expected to use the shapes produced by generic decoders: map[string]any for
+// objects, []any for arrays, and string, bool, nil or a number type for scalars.
+// Violations are returned in a stable order.
+func (s *Schema) Violations(doc any) []Violation {
+	var out []Violation
+	s.validate("$", doc, &out)
+	return out
+}
+
+func (s *Schema) validate(path string, v any, out *[]Violation) {
+	if s == nil {
+		return
+	}
+	fail := func(kind ViolationKind, format string, args ...any) {
+		*out = append(*out, Violation{Path: path, Kind: kind, Message: fmt.Sprintf(format, args...)})
+	}
+	if v == nil && s.Nullable {
+		return
+	}
+	if s.Type != "" && !matchesType(s.Type, v) {
+		fail(KindType, "expected %s, got %s", s.Type, jsonType(v))
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		fail(KindEnum, "value %v is not one of %v", v, s.Enum)
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if !hasProperty(val, name) {
+				fail(KindRequired, "missing required property %q", name)
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := path + "." + k
+			if prop, ok := s.property(k); ok {
+				prop.validate(child, val[k], out)
+				continue
+			}
+			switch extra := s.AdditionalProperties.(type) {
+			case bool:
+				if !extra {
+					*out = append(*out, Violation{Path: child, Kind: KindUnknown, Message: "unknown property"})
+				}
+			case *Schema:
+				extra.validate(child, val[k], out)
+			}
+		}
+	case []any:
+		for i, item := range val {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, out)
+		}
+	}
+}
+
+// property returns the schema for key k. Like encoding/json, which this
+// validates documents for, it prefers an exact match and otherwise accepts
+// a case-insensitive one.
+func (s *Schema) property(k string) (*Schema, bool) {
+	if prop, ok := s.Properties[k]; ok {
+		return prop, true
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.EqualFold(name, k) {
+			return s.Properties[name], true
+		}
+	}
+	return nil, false
+}
+
+// hasProperty reports whether obj holds name, matched as in property.
+func hasProperty(obj map[string]any, name string) bool {
+	if _, ok := obj[name]; ok {
+		return true
+	}
+	for k := range obj {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesType(want string, v any) bool {
+	got := jsonType(v)
+	if want == "number" && got == "integer" {
+		return true
+	}
+	return got == want
+}
+
+func jsonType(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		// Judge the literal, like encoding/json does: 1.0 and 1e3 do not
+		// decode into integer fields.
+		if strings.ContainsAny(val.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case float32:
+		return floatType(float64(val))
+	case float64:
+		return floatType(val)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "integer"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func floatType(f float64) string {
+	if f == math.Trunc(f) {
+		return "integer"
+	}
+	return "number"
+}
+
+func inEnum(enum []any, v any) bool {
+	got, err := json.Marshal(normalize(v))
+	if err != nil {
+		return false
+	}
+	for _, e := range enum {
+		want, err := json.Marshal(normalize(e))
+		if err == nil && bytes.Equal(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize converts numbers to float64 so that 1, 1.0 and json.Number("1") compare equal.
+func normalize(v any) any {
+	switch n := v.(type) {
+	case json.Number:
+		if f, err := n.Float64(); err == nil {
+			return f
+		}
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	}
+	return v
+}
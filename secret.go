@@ -0,0 +1,44 @@
+package confstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// isSecret reports whether field is tagged secret:"true", the single marker
+// honored by Fingerprint, View, MarshalRedacted and the manifest package.
+func isSecret(field reflect.StructField) bool {
+	return field.Tag.Get("secret") == "true"
+}
+
+// MarshalRedacted encodes config as JSON with the value of every field tagged
+// secret:"true" replaced by Redacted, whatever its type. The result is meant
+// for humans and tooling (logs, manifests, diffs); it does not necessarily
+// decode back into the config type.
+func MarshalRedacted(config any) ([]byte, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("confstore: redact encode config: %w", err)
+	}
+	if config == nil {
+		return data, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err = dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("confstore: redact decode config: %w", err)
+	}
+	tree = filterTree(reflect.TypeOf(config), tree, "", func(field reflect.StructField, _ string, v any) (any, bool) {
+		if isSecret(field) {
+			return Redacted, true
+		}
+		return v, true
+	})
+	if data, err = json.Marshal(tree); err != nil {
+		return nil, fmt.Errorf("confstore: redact encode redacted config: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,22 @@
+package confstore
+
+import "testing"
+
+func TestMarshalRedacted(t *testing.T) {
+	type db struct {
+		Host string `json:"host"`
+		Port int    `json:"port" secret:"true"`
+	}
+	type conf struct {
+		DB      db            `json:"db"`
+		Tenants map[string]db `json:"tenants"`
+	}
+	got, err := MarshalRedacted(&conf{DB: db{Host: "db", Port: 5432}, Tenants: map[string]db{"acme": {Port: 1}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"db":{"host":"db","port":"REDACTED"},"tenants":{"acme":{"host":"","port":"REDACTED"}}}`
+	if string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
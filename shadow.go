@@ -0,0 +1,137 @@
+package confstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-sphere/confstore/codec"
+	"github.com/go-sphere/confstore/provider"
+)
+
+// ErrShadowRejected indicates that at least one shadow evaluator rejected a candidate configuration.
+var ErrShadowRejected = errors.New("confstore: candidate config rejected by shadow evaluation")
+
+// ShadowEvaluator inspects a decoded candidate configuration before it is applied,
+// e.g. by replaying recorded traffic or running sanity simulations against it.
+// Returning a non-nil error rejects the candidate.
+type ShadowEvaluator[T any] func(ctx context.Context, candidate *T) error
+
+// LoadShadowWithContext reads and decodes a candidate configuration, then hands it to every evaluator.
+// The candidate is returned only if all evaluators pass; otherwise the evaluator errors are
+// joined with ErrShadowRejected.
+func LoadShadowWithContext[T any](ctx context.Context, provider provider.Provider, codec codec.Codec, evaluators ...ShadowEvaluator[T]) (*T, error) {
+	candidate, err := LoadWithContext[T](ctx, provider, codec)
+	if err != nil {
+		return nil, err
+	}
+	if err = evaluateShadow(ctx, candidate, evaluators); err != nil {
+		return nil, err
+	}
+	return candidate, nil
+}
+
+// LoadShadow reads a candidate configuration and returns it only if all evaluators pass.
+func LoadShadow[T any](provider provider.Provider, codec codec.Codec, evaluators ...ShadowEvaluator[T]) (*T, error) {
+	return LoadShadowWithContext[T](context.Background(), provider, codec, evaluators...)
+}
+
+// FillShadowWithContext decodes a candidate configuration into a deep copy of config and
+// evaluates it, so like Fill it keeps defaults and fields the document leaves out.
+// The live config is overwritten only if all evaluators pass, so a rejected candidate never
+// leaves it partially updated. Callers sharing config across goroutines must synchronize access.
+func FillShadowWithContext[T any](ctx context.Context, provider provider.Provider, codec codec.Codec, config *T, evaluators ...ShadowEvaluator[T]) error {
+	data, err := provider.Read(ctx)
+	if err != nil {
+		return err
+	}
+	candidate := new(T)
+	deepCopy(reflect.ValueOf(candidate).Elem(), reflect.ValueOf(config).Elem())
+	if err = codec.Unmarshal(data, candidate); err != nil {
+		return err
+	}
+	if err = evaluateShadow(ctx, candidate, evaluators); err != nil {
+		return err
+	}
+	*config = *candidate
+	return nil
+}
+
+// FillShadow decodes and evaluates a candidate configuration, applying it to config only if all evaluators pass.
+func FillShadow[T any](provider provider.Provider, codec codec.Codec, config *T, evaluators ...ShadowEvaluator[T]) error {
+	return FillShadowWithContext[T](context.Background(), provider, codec, config, evaluators...)
+}
+
+// deepCopy sets dst to a copy of src that shares no maps, slices or pointers
+// with it, so that decoding into dst cannot modify src. Unexported fields are
+// copied shallowly.
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		p := reflect.New(src.Elem().Type())
+		deepCopy(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		v := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(v, src.Elem())
+		dst.Set(v)
+	case reflect.Map:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			v := reflect.New(iter.Value().Type()).Elem()
+			deepCopy(v, iter.Value())
+			m.SetMapIndex(iter.Key(), v)
+		}
+		dst.Set(m)
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.Set(src)
+			return
+		}
+		sl := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(sl.Index(i), src.Index(i))
+		}
+		dst.Set(sl)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				deepCopy(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+func evaluateShadow[T any](ctx context.Context, candidate *T, evaluators []ShadowEvaluator[T]) error {
+	var joined error
+	for i, eval := range evaluators {
+		if err := eval(ctx, candidate); err != nil {
+			joined = errors.Join(joined, fmt.Errorf("evaluator[%d]: %w", i, err))
+		}
+	}
+	if joined != nil {
+		return errors.Join(ErrShadowRejected, joined)
+	}
+	return nil
+}
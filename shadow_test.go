@@ -0,0 +1,87 @@
+package confstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-sphere/confstore/codec"
+	"github.com/go-sphere/confstore/provider"
+)
+
+func staticProvider(s string) provider.Provider {
+	return provider.ReaderFunc(func(ctx context.Context) ([]byte, error) {
+		return []byte(s), nil
+	})
+}
+
+func TestFillShadow_AppliesWhenAllPass(t *testing.T) {
+	live := appConf{Addr: "old", Mode: "dev"}
+	var seen string
+	err := FillShadow(staticProvider(`{"addr":"new","mode":"prod"}`), codec.JsonCodec(), &live,
+		func(ctx context.Context, c *appConf) error {
+			seen = c.Addr
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen != "new" {
+		t.Fatalf("evaluator saw %q, want %q", seen, "new")
+	}
+	if live.Addr != "new" || live.Mode != "prod" {
+		t.Fatalf("candidate not applied: %+v", live)
+	}
+}
+
+func TestFillShadow_RejectedLeavesLiveUntouched(t *testing.T) {
+	boom := errors.New("replay failed")
+	live := appConf{Addr: "old", Mode: "dev"}
+	err := FillShadow(staticProvider(`{"addr":"new","mode":"prod"}`), codec.JsonCodec(), &live,
+		func(ctx context.Context, c *appConf) error { return nil },
+		func(ctx context.Context, c *appConf) error { return boom },
+	)
+	if !errors.Is(err, ErrShadowRejected) {
+		t.Fatalf("expected ErrShadowRejected, got %v", err)
+	}
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected joined evaluator error, got %v", err)
+	}
+	if live.Addr != "old" || live.Mode != "dev" {
+		t.Fatalf("live config modified: %+v", live)
+	}
+}
+
+func TestFillShadow_KeepsFieldsLikeFill(t *testing.T) {
+	type db struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type conf struct {
+		Addr   string            `json:"addr"`
+		DB     *db               `json:"db"`
+		Labels map[string]string `json:"labels"`
+	}
+	doc := `{"db":{"host":"new"},"labels":{"b":"2"}}`
+	reject := func(ctx context.Context, c *conf) error { return errors.New("no") }
+
+	live := conf{Addr: ":80", DB: &db{Host: "old", Port: 5432}, Labels: map[string]string{"a": "1"}}
+	if err := FillShadow(staticProvider(doc), codec.JsonCodec(), &live, reject); !errors.Is(err, ErrShadowRejected) {
+		t.Fatalf("expected ErrShadowRejected, got %v", err)
+	}
+	if live.DB.Host != "old" || len(live.Labels) != 1 {
+		t.Fatalf("rejected candidate leaked into live config: %+v %+v", live.DB, live.Labels)
+	}
+
+	filled := conf{Addr: ":80", DB: &db{Host: "old", Port: 5432}, Labels: map[string]string{"a": "1"}}
+	if err := Fill(staticProvider(doc), codec.JsonCodec(), &filled); err != nil {
+		t.Fatalf("Fill error: %v", err)
+	}
+	if err := FillShadow(staticProvider(doc), codec.JsonCodec(), &live); err != nil {
+		t.Fatalf("FillShadow error: %v", err)
+	}
+	if live.Addr != filled.Addr || *live.DB != *filled.DB || len(live.Labels) != len(filled.Labels) {
+		t.Fatalf("FillShadow = %+v %+v, Fill = %+v %+v", live, *live.DB, filled, *filled.DB)
+	}
+}
@@ -0,0 +1,77 @@
+package confstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-sphere/confstore/codec"
+)
+
+// ErrSnapshotCorrupt indicates that a snapshot could not be parsed or failed its integrity check.
+var ErrSnapshotCorrupt = errors.New("confstore: snapshot corrupt")
+
+const snapshotFormat = 1
+
+// SnapshotMeta describes the configuration captured in a snapshot.
+type SnapshotMeta struct {
+	// Version is an optional caller-defined config version (etag, revision, ...).
+	Version string `json:"version,omitempty"`
+	// Source records where the configuration was loaded from, e.g. a provider URI.
+	Source string `json:"source,omitempty"`
+	// CreatedAt is the time the snapshot was taken. Snapshot fills it in when zero.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type snapshotEnvelope struct {
+	Format   int          `json:"format"`
+	Meta     SnapshotMeta `json:"meta"`
+	Checksum []byte       `json:"checksum"`
+	Config   []byte       `json:"config"`
+}
+
+// Snapshot encodes config with the given codec and writes it to w together with
+// its metadata and a checksum, so a restarted process can restore the exact
+// configuration state via RestoreSnapshot before its remote source is reachable.
+func Snapshot(w io.Writer, codec codec.Codec, config any, meta SnapshotMeta) error {
+	data, err := codec.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("confstore: snapshot encode config: %w", err)
+	}
+	if meta.CreatedAt.IsZero() {
+		meta.CreatedAt = time.Now()
+	}
+	sum := sha256.Sum256(data)
+	return json.NewEncoder(w).Encode(snapshotEnvelope{
+		Format:   snapshotFormat,
+		Meta:     meta,
+		Checksum: sum[:],
+		Config:   data,
+	})
+}
+
+// RestoreSnapshot reads a snapshot written by Snapshot, verifies its checksum and
+// decodes the configuration with the given codec, which must match the one used
+// to write it.
+func RestoreSnapshot[T any](r io.Reader, codec codec.Codec) (*T, *SnapshotMeta, error) {
+	var env snapshotEnvelope
+	if err := json.NewDecoder(r).Decode(&env); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrSnapshotCorrupt, err)
+	}
+	if env.Format != snapshotFormat {
+		return nil, nil, fmt.Errorf("%w: unsupported format %d", ErrSnapshotCorrupt, env.Format)
+	}
+	sum := sha256.Sum256(env.Config)
+	if !bytes.Equal(sum[:], env.Checksum) {
+		return nil, nil, fmt.Errorf("%w: checksum mismatch", ErrSnapshotCorrupt)
+	}
+	var config T
+	if err := codec.Unmarshal(env.Config, &config); err != nil {
+		return nil, nil, fmt.Errorf("confstore: snapshot decode config: %w", err)
+	}
+	return &config, &env.Meta, nil
+}
@@ -0,0 +1,42 @@
+package confstore
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/go-sphere/confstore/codec"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	in := appConf{Addr: "127.0.0.1:8080", Mode: "prod"}
+	if err := Snapshot(&buf, codec.JsonCodec(), in, SnapshotMeta{Version: "v7", Source: "https://cfg/app.json"}); err != nil {
+		t.Fatalf("Snapshot error: %v", err)
+	}
+	out, meta, err := RestoreSnapshot[appConf](&buf, codec.JsonCodec())
+	if err != nil {
+		t.Fatalf("RestoreSnapshot error: %v", err)
+	}
+	if *out != in {
+		t.Fatalf("got %+v, want %+v", *out, in)
+	}
+	if meta.Version != "v7" || meta.Source != "https://cfg/app.json" || meta.CreatedAt.IsZero() {
+		t.Fatalf("unexpected meta: %+v", meta)
+	}
+}
+
+func TestRestoreSnapshot_Tampered(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Snapshot(&buf, codec.JsonCodec(), appConf{Addr: "a"}, SnapshotMeta{}); err != nil {
+		t.Fatalf("Snapshot error: %v", err)
+	}
+	// Flip one byte inside the base64 config payload.
+	raw := buf.Bytes()
+	i := bytes.Index(raw, []byte(`"config":"`)) + len(`"config":"`)
+	raw[i] ^= 0x01
+	_, _, err := RestoreSnapshot[appConf](bytes.NewReader(raw), codec.JsonCodec())
+	if !errors.Is(err, ErrSnapshotCorrupt) {
+		t.Fatalf("expected ErrSnapshotCorrupt, got %v", err)
+	}
+}